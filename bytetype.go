@@ -0,0 +1,96 @@
+package hexdump
+
+/*
+	The od style types of the value column, where each byte can be
+	shown in hex (the default), signed or unsigned decimal, octal or
+	as a character, as od -t x1, d1, u1, o1 and c do.
+
+	Edits:
+
+		2026-10-14		lc 		Created from scratch
+
+	Copyright (c) 2020 NOVA Industries Limited
+
+	No warrenty implied or otherwise
+
+*/
+
+import "strconv"
+
+// ByteType selects how each byte of the value column is shown
+type ByteType int
+
+const (
+	HexByte      ByteType = iota // two hex digits, the default (od -t x1)
+	SignedByte                   // signed decimal, -128 to 127 (od -t d1)
+	UnsignedByte                 // unsigned decimal, 0 to 255 (od -t u1)
+	OctalByte                    // three octal digits (od -t o1)
+	CharByte                     // the character or a C escape (od -t c)
+)
+
+// charEscapes are the C escapes od -t c shows for control characters.
+// The other bytes that are not printable are shown in octal.
+var charEscapes = map[byte]string{
+	0x00: `\0`,
+	0x07: `\a`,
+	0x08: `\b`,
+	0x09: `\t`,
+	0x0a: `\n`,
+	0x0b: `\v`,
+	0x0c: `\f`,
+	0x0d: `\r`,
+}
+
+// typeDigits returns the width each byte takes in the value column for
+// the type. Binary only applies to HexByte.
+func typeDigits(byteType ByteType, binary bool) int {
+
+	switch byteType {
+	case SignedByte:
+		return 4
+	case UnsignedByte, OctalByte, CharByte:
+		return 3
+	}
+
+	if binary {
+		return 8
+	}
+	return 2
+}
+
+// appendByte appends the value of one byte to buffer, in the dumper's
+// type, right aligned to byteDigits characters as od does
+func (d *dumper) appendByte(buffer []byte, ch byte) []byte {
+
+	var text []byte
+	switch d.byteType {
+	case SignedByte:
+		text = strconv.AppendInt(nil, int64(int8(ch)), 10)
+	case UnsignedByte:
+		text = strconv.AppendUint(nil, uint64(ch), 10)
+	case OctalByte:
+		text = []byte{'0' + ch>>6, '0' + ch>>3&7, '0' + ch&7}
+	case CharByte:
+		switch escape, ok := charEscapes[ch]; {
+		case ok:
+			text = []byte(escape)
+		case isPrintable(ch):
+			text = []byte{ch}
+		default:
+			text = []byte{'0' + ch>>6, '0' + ch>>3&7, '0' + ch&7}
+		}
+	default:
+		if d.binary {
+			for bit := 7; bit >= 0; bit-- {
+				buffer = append(buffer, '0'+ch>>bit&1)
+			}
+			return buffer
+		}
+		return append(buffer, d.hexDigits[ch>>4], d.hexDigits[ch&0x0F])
+	}
+
+	for n := len(text); n < d.byteDigits; n++ {
+		buffer = append(buffer, ' ')
+	}
+	return append(buffer, text...)
+}
@@ -0,0 +1,75 @@
+package hexdump
+
+/*
+	The canonical layout of hexdump -C, for scripts written to parse
+	it. The layout is fixed: 16 bytes a line in two groups of 8, the
+	characters between pipes, and the offset of the end on a line of
+	its own:
+
+		00000000  68 65 6c 6c 6f 2c 20 77  6f 72 6c 64 0a           |hello, world.|
+		0000000d
+
+	Edits:
+
+		2026-10-14		lc 		Created from scratch
+
+	Copyright (c) 2020 NOVA Industries Limited
+
+	No warrenty implied or otherwise
+
+*/
+
+import "fmt"
+
+const canonicalGroup = 8 // bytes in each half of a canonical line
+
+// canonicalOptions returns the options a Canonical dump is made with.
+// Only the options that pick the bytes to dump, and Squeeze and Step,
+// are kept; everything about the layout is fixed.
+func canonicalOptions(opts Options) Options {
+
+	return Options{
+		Format:   Canonical,
+		Width:    NormalWidth,
+		Squeeze:  opts.Squeeze,
+		Step:     opts.Step,
+		Skip:     opts.Skip,
+		Relative: opts.Relative,
+		Find:     opts.Find,
+		Length:   opts.Length,
+		Tee:      opts.Tee,
+		Totals:   opts.Totals,
+	}
+}
+
+// printCanonical writes one line of the dump in the hexdump -C layout.
+// A short line has its hex column padded with spaces so that the
+// characters start in the same place as on a full line.
+func (d *dumper) printCanonical(line Line) {
+
+	buffer := fmt.Appendf(d.lineBuffer[:0], "%08x ", line.Offset)
+
+	for i := 0; i < d.displayWidth; i++ {
+		if i%canonicalGroup == 0 {
+			buffer = append(buffer, ' ')
+		}
+		if i < len(line.Bytes) {
+			ch := line.Bytes[i]
+			buffer = append(buffer, lowerHexDigits[ch>>4], lowerHexDigits[ch&0x0F], ' ')
+		} else {
+			buffer = append(buffer, "   "...)
+		}
+	}
+
+	buffer = append(buffer, " |"...)
+	for _, ch := range line.Bytes {
+		if !isPrintable(ch) {
+			ch = '.'
+		}
+		buffer = append(buffer, ch)
+	}
+	buffer = append(buffer, "|\n"...)
+
+	d.lineBuffer = buffer
+	d.w.Write(buffer)
+}
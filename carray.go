@@ -0,0 +1,83 @@
+package hexdump
+
+/*
+	CArray writes a stream as a C unsigned char array, in the style of
+	"xxd -i", so that a binary can be embedded in source code:
+
+		unsigned char data[] = {
+		  0x12, 0x34, ...
+		};
+		unsigned int data_len = 2;
+
+	Edits:
+
+		2026-10-14		lc 		Created from scratch
+		2026-10-14		lc 		Find option applied
+		2026-10-14		lc 		BufferSize option applied
+
+	Copyright (c) 2020 NOVA Industries Limited
+
+	No warrenty implied or otherwise
+
+*/
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+const cBytesPerLine = 12
+
+// CArray writes the content of the stream r to w as a C array called
+// name, followed by a name_len variable holding the number of bytes.
+// The Skip, Find, Length, Tee, BufferSize and Upper options are used,
+// the rest only apply to Dump.
+func CArray(w io.Writer, r io.Reader, name string, opts Options) error {
+
+	r, _, err := limitInput(r, opts)
+	if err != nil {
+		return err
+	}
+
+	out := bufio.NewWriter(w)
+	byteFormat := "0x" + caseFormat(hex8Bits, opts.Upper)
+
+	fmt.Fprintf(out, "unsigned char %s[] = {\n", name)
+
+	buffer := make([]byte, readBufferSize(opts))
+	var count uint64
+
+	for {
+		bufferRead, err := r.Read(buffer)
+
+		for _, ch := range buffer[:bufferRead] {
+			switch {
+			case count == 0:
+				out.WriteString("  ")
+			case count%cBytesPerLine == 0:
+				out.WriteString(",\n  ")
+			default:
+				out.WriteString(", ")
+			}
+
+			fmt.Fprintf(out, byteFormat, ch)
+			count++
+		}
+
+		if err != nil {
+			if err != io.EOF {
+				out.Flush()
+				return err
+			}
+			break
+		}
+	}
+
+	if count > 0 {
+		out.WriteString("\n")
+	}
+	fmt.Fprintf(out, "};\nunsigned int %s_len = %d;\n", name, count)
+
+	return out.Flush()
+}
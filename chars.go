@@ -0,0 +1,206 @@
+package hexdump
+
+/*
+	The mapping from a byte to the text shown for it in the ASCII
+	column of a dump.
+
+	Edits:
+
+		2026-10-14		lc 		Created from scratch
+		2026-10-14		lc 		Control character mnemonics
+		2026-10-14		lc 		appendChar for building lines in place
+		2026-10-14		lc 		EBCDIC character column
+		2026-10-14		lc 		Optional 7-bit stripping
+		2026-10-14		lc 		Optional UTF-8 decoding
+
+	Copyright (c) 2020 NOVA Industries Limited
+
+	No warrenty implied or otherwise
+
+*/
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	latin1First = 0xA0 // first printable Latin-1 byte above ASCII
+
+	// utf8Continuation is shown for each byte of a UTF-8 character
+	// after the first, so that the column keeps a character per byte
+	utf8Continuation = '_'
+)
+
+// mnemonics are the ASCII names of the control characters. TAB is used
+// rather than HT as it is the better known name.
+var mnemonics = [...]string{
+	"NUL", "SOH", "STX", "ETX", "EOT", "ENQ", "ACK", "BEL",
+	"BS", "TAB", "LF", "VT", "FF", "CR", "SO", "SI",
+	"DLE", "DC1", "DC2", "DC3", "DC4", "NAK", "SYN", "ETB",
+	"CAN", "EM", "SUB", "ESC", "FS", "GS", "RS", "US",
+}
+
+// mnemonic returns the name of a control character, or "" if ch is
+// not one
+func mnemonic(ch byte) string {
+
+	switch {
+	case int(ch) < len(mnemonics):
+		return mnemonics[ch]
+	case ch == chDel:
+		return "DEL"
+	default:
+		return ""
+	}
+}
+
+// ebcdicRunes maps each EBCDIC (code page 037, US/Canada) byte to the
+// Unicode character it stands for. The control characters, and the
+// bytes that have no printable character, are 0.
+var ebcdicRunes = [256]rune{
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // 00
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // 08
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // 10
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // 18
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // 20
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // 28
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // 30
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // 38
+	0x20, 0xA0, 0xE2, 0xE4, 0xE0, 0xE1, 0xE3, 0xE5, // 40
+	0xE7, 0xF1, 0xA2, 0x2E, 0x3C, 0x28, 0x2B, 0x7C, // 48
+	0x26, 0xE9, 0xEA, 0xEB, 0xE8, 0xED, 0xEE, 0xEF, // 50
+	0xEC, 0xDF, 0x21, 0x24, 0x2A, 0x29, 0x3B, 0xAC, // 58
+	0x2D, 0x2F, 0xC2, 0xC4, 0xC0, 0xC1, 0xC3, 0xC5, // 60
+	0xC7, 0xD1, 0xA6, 0x2C, 0x25, 0x5F, 0x3E, 0x3F, // 68
+	0xF8, 0xC9, 0xCA, 0xCB, 0xC8, 0xCD, 0xCE, 0xCF, // 70
+	0xCC, 0x60, 0x3A, 0x23, 0x40, 0x27, 0x3D, 0x22, // 78
+	0xD8, 0x61, 0x62, 0x63, 0x64, 0x65, 0x66, 0x67, // 80
+	0x68, 0x69, 0xAB, 0xBB, 0xF0, 0xFD, 0xFE, 0xB1, // 88
+	0xB0, 0x6A, 0x6B, 0x6C, 0x6D, 0x6E, 0x6F, 0x70, // 90
+	0x71, 0x72, 0xAA, 0xBA, 0xE6, 0xB8, 0xC6, 0xA4, // 98
+	0xB5, 0x7E, 0x73, 0x74, 0x75, 0x76, 0x77, 0x78, // A0
+	0x79, 0x7A, 0xA1, 0xBF, 0xD0, 0xDD, 0xDE, 0xAE, // A8
+	0x5E, 0xA3, 0xA5, 0xB7, 0xA9, 0xA7, 0xB6, 0xBC, // B0
+	0xBD, 0xBE, 0x5B, 0x5D, 0xAF, 0xA8, 0xB4, 0xD7, // B8
+	0x7B, 0x41, 0x42, 0x43, 0x44, 0x45, 0x46, 0x47, // C0
+	0x48, 0x49, 0x00, 0xF4, 0xF6, 0xF2, 0xF3, 0xF5, // C8
+	0x7D, 0x4A, 0x4B, 0x4C, 0x4D, 0x4E, 0x4F, 0x50, // D0
+	0x51, 0x52, 0xB9, 0xFB, 0xFC, 0xF9, 0xFA, 0xFF, // D8
+	0x5C, 0xF7, 0x53, 0x54, 0x55, 0x56, 0x57, 0x58, // E0
+	0x59, 0x5A, 0xB2, 0xD4, 0xD6, 0xD2, 0xD3, 0xD5, // E8
+	0x30, 0x31, 0x32, 0x33, 0x34, 0x35, 0x36, 0x37, // F0
+	0x38, 0x39, 0xB3, 0xDB, 0xDC, 0xD9, 0xDA, 0x00, // F8
+}
+
+// charMapper turns a byte into the text shown for it in the ASCII
+// column. The zero value shows printable ASCII and a "." for the rest.
+type charMapper struct {
+	dot    string
+	latin1 bool
+	ebcdic bool
+	strip7 bool // the high (parity) bit is cleared first
+
+	decodeUTF8 bool // multi-byte UTF-8 characters are decoded
+}
+
+// newCharMapper returns a charMapper for the options
+func newCharMapper(opts Options) charMapper {
+
+	c := charMapper{
+		dot:    ".",
+		latin1: opts.Latin1 && !opts.EBCDIC && !opts.Strip7 && !opts.UTF8,
+		ebcdic: opts.EBCDIC,
+		strip7: opts.Strip7 && !opts.EBCDIC,
+
+		decodeUTF8: opts.UTF8 && !opts.EBCDIC && !opts.Strip7,
+	}
+	if opts.Dot != 0 {
+		c.dot = string(opts.Dot)
+	}
+
+	return c
+}
+
+// value returns the byte ch is read as, which is ch with the high bit
+// cleared when stripping to 7 bits
+func (c charMapper) value(ch byte) byte {
+
+	if c.strip7 {
+		return ch & 0x7F
+	}
+
+	return ch
+}
+
+// char returns the text shown for the byte ch
+func (c charMapper) char(ch byte) string {
+
+	return string(c.appendChar(nil, ch))
+}
+
+// printable reports whether ch is shown as a character rather than as
+// the dot. Latin-1 bytes are not counted, so that they keep the colour
+// of the other high bytes.
+func (c charMapper) printable(ch byte) bool {
+
+	if c.ebcdic {
+		return ebcdicRunes[ch] != 0
+	}
+
+	return isPrintable(c.value(ch))
+}
+
+// runeSize returns the number of bytes at the start of data that are
+// shown as one character. It is 1 unless UTF-8 is decoded and data
+// starts with a printable character of more than one byte. A character
+// cut off by the end of data is not decoded.
+func (c charMapper) runeSize(data []byte) int {
+
+	if !c.decodeUTF8 {
+		return 1
+	}
+
+	r, size := utf8.DecodeRune(data)
+	if size < 2 || !unicode.IsPrint(r) {
+		return 1
+	}
+
+	return size
+}
+
+// appendRune appends the character taking the first size bytes of
+// data, as found by runeSize, and a placeholder for each byte after
+// the first
+func (c charMapper) appendRune(buffer []byte, data []byte, size int) []byte {
+
+	if size == 1 {
+		return c.appendChar(buffer, data[0])
+	}
+
+	buffer = append(buffer, data[:size]...)
+	for range size - 1 {
+		buffer = append(buffer, utf8Continuation)
+	}
+
+	return buffer
+}
+
+// appendChar appends the text shown for the byte ch to buffer
+func (c charMapper) appendChar(buffer []byte, ch byte) []byte {
+
+	ch = c.value(ch)
+
+	switch {
+	case c.ebcdic && ebcdicRunes[ch] != 0:
+		return utf8.AppendRune(buffer, ebcdicRunes[ch])
+	case !c.ebcdic && isPrintable(ch):
+		return append(buffer, ch)
+	case c.latin1 && ch >= latin1First:
+		return utf8.AppendRune(buffer, rune(ch))
+	case c.dot == "":
+		return append(buffer, '.')
+	default:
+		return append(buffer, c.dot...)
+	}
+}
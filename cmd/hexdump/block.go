@@ -0,0 +1,90 @@
+package main
+
+/*
+	The -block dump of chosen blocks of an input, such as the sectors of
+	a disk image, each under a header of its own. With -block-size 16:
+
+		==> block 3 <==
+		0030 :  32 30 0a 32 31 0a 32 32 0a 32 33 0a 32 34 0a 32  : 20.21.22.23.24.2
+
+	Edits:
+
+		2026-10-14		lc 		Created for -block
+
+	Copyright (c) 2020 NOVA Industries Limited
+
+	No warrenty implied or otherwise
+
+*/
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/liam-collins/go-hexdump"
+)
+
+// errNoSeek is returned by dumpBlocks for an input, such as a pipe, that
+// cannot seek back to an earlier block
+
+var errNoSeek = errors.New("-block needs an input that can seek")
+
+// blockPastEnd is the error for a block that starts at or after the end
+// of the input
+
+type blockPastEnd struct {
+	block uint64
+}
+
+func (e blockPastEnd) Error() string {
+
+	return fmt.Sprintf("block %d is past the end", e.block)
+}
+
+// dumpBlocks dumps each of the blocks of size bytes of r, in the order
+// 		given, with a "==> block N <==" header above each. The offsets
+// 		are those of the input. A block that the input ends in the
+// 		middle of is dumped short. If any block starts at or after the
+// 		end nothing is dumped and a blockPastEnd error is returned.
+
+func dumpBlocks(w io.Writer, r io.Reader, blocks []uint64, size uint64, opts hexdump.Options, dump func(io.Reader, hexdump.Options) error) error {
+
+	seeker, ok := r.(io.Seeker)
+	if !ok {
+		return errNoSeek
+	}
+
+	end, err := seeker.Seek(0, io.SeekEnd)
+	if err != nil {
+		return errNoSeek
+	}
+
+	// Every block is checked first, so that nothing is dumped if any
+	// of them is missing
+	for _, block := range blocks {
+		if block*size >= uint64(end) {
+			return blockPastEnd{block: block}
+		}
+	}
+
+	for i, block := range blocks {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "==> block %d <==\n", block)
+
+		// Skip seeks on from where the stream is, so each block is
+		// found from the start
+		if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+
+		opts.Skip, opts.Length = block*size, size
+		if err := dump(r, opts); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
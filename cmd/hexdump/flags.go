@@ -0,0 +1,254 @@
+package main
+
+/*
+	Custom flag types used by the hexdump command line.
+
+	Edits:
+
+		2026-10-14		lc 		Created for the -skip option
+		2026-10-14		lc 		Added byteRange for -range
+		2026-10-14		lc 		Added hexBytes for -find
+		2026-10-14		lc 		Added sizeFlag for -buffer-size
+		2026-10-14		lc 		Odd number of hex digits reported as such
+		2026-10-14		lc 		Added blockList for -block
+		2026-10-14		lc 		Added timeField for -time-at
+
+	Copyright (c) 2020 NOVA Industries Limited
+
+	No warrenty implied or otherwise
+
+*/
+
+import (
+	"encoding/hex"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/liam-collins/go-hexdump"
+)
+
+// byteCount is a flag.Value holding a count of bytes. The value can
+// be given in decimal or, with a leading "0x", in hex.
+
+type byteCount uint64
+
+func (b *byteCount) String() string {
+
+	return strconv.FormatUint(uint64(*b), 10)
+}
+
+func (b *byteCount) Set(value string) error {
+
+	base := 10
+	if strings.HasPrefix(value, "0x") || strings.HasPrefix(value, "0X") {
+		value = value[2:]
+		base = 16
+	}
+
+	n, err := strconv.ParseUint(value, base, 64)
+	if err != nil {
+		return errors.New("expected a decimal or 0x prefixed hex number")
+	}
+
+	*b = byteCount(n)
+	return nil
+}
+
+// sizeFlag is a flag.Value holding a size in bytes, given in decimal
+// with an optional K, M or G suffix for KiB, MiB or GiB, such as "64K".
+
+type sizeFlag uint64
+
+func (b *sizeFlag) String() string {
+
+	return strconv.FormatUint(uint64(*b), 10)
+}
+
+func (b *sizeFlag) Set(value string) error {
+
+	var shift uint
+	switch {
+	case strings.HasSuffix(value, "K"), strings.HasSuffix(value, "k"):
+		shift = 10
+	case strings.HasSuffix(value, "M"), strings.HasSuffix(value, "m"):
+		shift = 20
+	case strings.HasSuffix(value, "G"), strings.HasSuffix(value, "g"):
+		shift = 30
+	}
+	if shift > 0 {
+		value = value[:len(value)-1]
+	}
+
+	n, err := strconv.ParseUint(value, 10, 64)
+	if err != nil || n > math.MaxUint64>>shift {
+		return errors.New("expected a decimal number, with an optional K, M or G suffix")
+	}
+
+	*b = sizeFlag(n << shift)
+	return nil
+}
+
+// blockList is a flag.Value holding the block numbers of every -block
+// given, in order. Each can be decimal or 0x prefixed hex.
+
+type blockList []uint64
+
+func (b *blockList) String() string {
+
+	if b == nil {
+		return ""
+	}
+
+	numbers := make([]string, len(*b))
+	for i, n := range *b {
+		numbers[i] = strconv.FormatUint(n, 10)
+	}
+	return strings.Join(numbers, ",")
+}
+
+func (b *blockList) Set(value string) error {
+
+	var n byteCount
+	if err := n.Set(value); err != nil {
+		return err
+	}
+
+	*b = append(*b, uint64(n))
+	return nil
+}
+
+// timeField is a flag.Value holding the OFFSET,SIZE,ENDIAN of a Unix
+// time in each record, such as "8,4,little". OFFSET can be decimal or
+// 0x prefixed hex, SIZE is 4 or 8 and ENDIAN big or little.
+
+type timeField struct {
+	hexdump.TimeField
+	set bool
+}
+
+func (t *timeField) String() string {
+
+	if t == nil || !t.set {
+		return ""
+	}
+
+	endian := "big"
+	if t.LittleEndian {
+		endian = "little"
+	}
+	return strconv.FormatUint(t.Offset, 10) + "," + strconv.Itoa(t.Size) + "," + endian
+}
+
+func (t *timeField) Set(value string) error {
+
+	parts := strings.Split(value, ",")
+	if len(parts) != 3 {
+		return errors.New("expected OFFSET,SIZE,ENDIAN such as 8,4,little")
+	}
+
+	var offset byteCount
+	if err := offset.Set(parts[0]); err != nil {
+		return err
+	}
+
+	size, err := strconv.Atoi(parts[1])
+	if err != nil || size != 4 && size != 8 {
+		return errors.New("the SIZE must be 4 or 8")
+	}
+
+	switch parts[2] {
+	case "big":
+		t.LittleEndian = false
+	case "little":
+		t.LittleEndian = true
+	default:
+		return errors.New("the ENDIAN must be big or little")
+	}
+
+	t.Offset = uint64(offset)
+	t.Size = size
+	t.set = true
+	return nil
+}
+
+// byteRange is a flag.Value holding a START:END range of bytes. Either
+// bound can be left out: START defaults to 0 and END to the end of the
+// input. END is exclusive.
+
+type byteRange struct {
+	start  uint64
+	end    uint64
+	hasEnd bool
+}
+
+func (b *byteRange) String() string {
+
+	if b == nil {
+		return ""
+	}
+
+	text := strconv.FormatUint(b.start, 10) + ":"
+	if b.hasEnd {
+		text += strconv.FormatUint(b.end, 10)
+	}
+	return text
+}
+
+func (b *byteRange) Set(value string) error {
+
+	i := strings.Index(value, ":")
+	if i < 0 {
+		return errors.New("expected START:END")
+	}
+
+	var start, end byteCount
+
+	if value[:i] != "" {
+		if err := start.Set(value[:i]); err != nil {
+			return err
+		}
+	}
+
+	b.hasEnd = value[i+1:] != ""
+	if b.hasEnd {
+		if err := end.Set(value[i+1:]); err != nil {
+			return err
+		}
+		if end < start {
+			return errors.New("START is after END")
+		}
+	}
+
+	b.start = uint64(start)
+	b.end = uint64(end)
+	return nil
+}
+
+// hexBytes is a flag.Value holding a byte string given in hex, such as
+// "cafebabe". Spaces between the bytes are allowed, as in "ca fe ba be".
+
+type hexBytes []byte
+
+func (h *hexBytes) String() string {
+
+	if h == nil {
+		return ""
+	}
+	return hex.EncodeToString(*h)
+}
+
+func (h *hexBytes) Set(value string) error {
+
+	decoded, err := hex.DecodeString(strings.Join(strings.Fields(value), ""))
+	if err == hex.ErrLength {
+		return errors.New("odd number of hex digits, expected two for each byte")
+	}
+	if err != nil || len(decoded) == 0 {
+		return errors.New("expected hex bytes, such as cafebabe")
+	}
+
+	*h = decoded
+	return nil
+}
@@ -0,0 +1,1869 @@
+package main
+
+/*
+	hexdump outputs a hex and ASCII dump, of a io stream, to STDOUT.
+
+	The program will either read in from STDIN or take 1 or more
+	REGULAR files and process them as an IO stream. If a given file
+	is not a REGULAR file or the user does not have persmission to
+	the file then it is skipped. An http:// or https:// URL is
+	fetched and its body dumped, and "-" is STDIN, so files and
+	STDIN can be mixed.
+
+	The exit status is 0 if every input was dumped, 1 if there was
+	an error or any input was skipped (the remaining inputs are still
+	dumped), 2 if the command line options could not be parsed and
+	130 if the dump was interrupted with Ctrl-C.
+
+	Edits:
+
+		2020-08-26		lc 		Created from scratch
+		2026-10-14		lc 		hexdump writes to an io.Writer rather than STDOUT
+		2026-10-14		lc 		Thin CLI wrapper around the hexdump package
+		2026-10-14		lc 		Added -c to squeeze duplicate lines
+		2026-10-14		lc 		Added -skip to start the dump at an offset
+		2026-10-14		lc 		Added -length to limit the bytes dumped
+		2026-10-14		lc 		Added -r to convert a dump back to binary
+		2026-10-14		lc 		Added -A to choose the offset radix
+		2026-10-14		lc 		Added -u for upper case hex
+		2026-10-14		lc 		Added -g to group the hex bytes
+		2026-10-14		lc 		Added -color
+		2026-10-14		lc 		Added -format json
+		2026-10-14		lc 		Added -i to write a C array
+		2026-10-14		lc 		Added -range as an alternative to -skip/-length
+		2026-10-14		lc 		Added -diff to compare two files
+		2026-10-14		lc 		File name headers when dumping several files
+		2026-10-14		lc 		Added -z to read gzip compressed input
+		2026-10-14		lc 		Added -stats
+		2026-10-14		lc 		Added -sum for a checksum footer
+		2026-10-14		lc 		Added -dot to choose the non printable placeholder
+		2026-10-14		lc 		Added -latin1
+		2026-10-14		lc 		Added -mnemonic
+		2026-10-14		lc 		Squeeze by default, -v to print every line
+		2026-10-14		lc 		http and https URLs can be dumped
+		2026-10-14		lc 		Added -o to write the output to a file
+		2026-10-14		lc 		Exit status 1 if any input was skipped or failed
+		2026-10-14		lc 		Added -width for any number of bytes per line
+		2026-10-14		lc 		Flag checks moved into validateFlags
+		2026-10-14		lc 		Ctrl-C stops the dump cleanly with status 130
+		2026-10-14		lc 		Added -ebcdic
+		2026-10-14		lc 		Added -word and -endian
+		2026-10-14		lc 		Added -header for a ruler line
+		2026-10-14		lc 		Added -p for plain hex output
+		2026-10-14		lc 		Added -b for a binary byte column
+		2026-10-14		lc 		Added -stdin-name to label STDIN
+		2026-10-14		lc 		Added -find to start at a byte pattern
+		2026-10-14		lc 		Added -highlight to mark a byte pattern
+		2026-10-14		lc 		Added -grep and -context
+		2026-10-14		lc 		Added -trim to collapse padding
+		2026-10-14		lc 		A "-" argument is STDIN
+		2026-10-14		lc 		Added -addr-width, STDIN offsets are 32bit
+		2026-10-14		lc 		Added -check to validate the files only
+		2026-10-14		lc 		Say why a file is skipped
+		2026-10-14		lc 		Added -recursive to dump the files in directories
+		2026-10-14		lc 		Added -j to dump several files at once
+		2026-10-14		lc 		Added -progress
+		2026-10-14		lc 		Added -no-ascii
+		2026-10-14		lc 		Added -sep for the column separator
+		2026-10-14		lc 		Added -format csv
+		2026-10-14		lc 		Added -tee to save the input while dumping it
+		2026-10-14		lc 		Added -any to read pipes and devices
+		2026-10-14		lc 		Added -cmp and -quiet
+		2026-10-14		lc 		Added -template to lay out each line
+		2026-10-14		lc 		Added -histogram
+		2026-10-14		lc 		Added -magic
+		2026-10-14		lc 		Added -strip7
+		2026-10-14		lc 		Added -lineno
+		2026-10-14		lc 		Added -crlf
+		2026-10-14		lc 		The width is limited to 256 bytes
+		2026-10-14		lc 		Added -relative
+		2026-10-14		lc 		Added -files-from
+		2026-10-14		lc 		Added -count
+		2026-10-14		lc 		Added -step to sample the lines
+		2026-10-14		lc 		Added -right-offset
+		2026-10-14		lc 		Added -summary
+		2026-10-14		lc 		Added -C for the hexdump -C layout
+		2026-10-14		lc 		Added -t for od style byte types
+		2026-10-14		lc 		Added -addr-digits
+		2026-10-14		lc 		Added -record
+		2026-10-14		lc 		-quiet also silences the skip warnings
+		2026-10-14		lc 		Read errors written to STDERR
+		2026-10-14		lc 		Added -buffer-size
+		2026-10-14		lc 		Added -utf8
+		2026-10-14		lc 		Added -goescape and -cescape
+		2026-10-14		lc 		Dump cut short by a read error marked as such
+		2026-10-14		lc 		Added -from-hex
+		2026-10-14		lc 		Added -from-base64
+		2026-10-14		lc 		Added -ref to show only the lines that differ from a reference
+		2026-10-14		lc 		Added -ascii-first
+		2026-10-14		lc 		Added -line-crc
+		2026-10-14		lc 		Added -mmap
+		2026-10-14		lc 		Added -sparse
+		2026-10-14		lc 		Added -repeat-marker
+		2026-10-14		lc 		Added -block and -block-size
+		2026-10-14		lc 		Added -delta
+		2026-10-14		lc 		Added -files-from0
+		2026-10-14		lc 		Added -time-at
+		2026-10-14		lc 		Added -no-offset
+		2026-10-14		lc 		-r reads the offsets in the -A radix
+		2026-10-14		lc 		Read error marker only after a read error
+		2026-10-14		lc 		-mmap dumps straight from the mapping
+		2026-10-14		lc 		-cols must be even
+		2026-10-14		lc 		Input that is not gzip keeps its Seek with -z
+		2026-10-14		lc 		-length 0 is an error rather than no limit
+		2026-10-14		lc 		-sparse cannot be used with -lineno
+
+	Copyright (c) 2020 NOVA Industries Limited
+
+	No warrenty implied or otherwise
+
+*/
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"github.com/liam-collins/go-hexdump"
+)
+
+const (
+	maxUint16 = ^uint16(0)
+	maxUint32 = ^uint32(0)
+
+	maxBufferSize = 1 << 30
+
+	// inlineInput stands in the list of inputs for the bytes given on
+	// the command line. No file can have the name as it holds a NUL.
+	inlineInput = "\x00inline"
+
+	// readErrorMarker ends a dump that a read error cut short, so that
+	// it cannot be taken for the whole input
+	readErrorMarker = "...read error"
+)
+
+// errNotRegular is the reason a file that is not a regular file, such
+// as a directory or a device, is skipped
+
+var errNotRegular = errors.New("not a regular file")
+
+// fileError is the reason a file cannot be opened, in a few words. It
+// 		wraps the error it was made from, so errors.Is still finds
+// 		errNotRegular, fs.ErrNotExist or fs.ErrPermission.
+
+type fileError struct {
+	name   string
+	reason string
+	err    error
+}
+
+func (e fileError) Error() string {
+
+	return e.name + ": " + e.reason
+}
+
+func (e fileError) Unwrap() error {
+
+	return e.err
+}
+
+// newFileError gives err a short reason when it is one of the common
+// 		ones. Any other error keeps its own text as the reason.
+
+func newFileError(filename string, err error) error {
+
+	reason := err.Error()
+	switch {
+	case errors.Is(err, errNotRegular):
+		reason = errNotRegular.Error()
+	case errors.Is(err, fs.ErrNotExist):
+		reason = "not found"
+	case errors.Is(err, fs.ErrPermission):
+		reason = "permission denied"
+	}
+
+	return fileError{name: filename, reason: reason, err: err}
+}
+
+// The command line flags. They are package variables so that
+// validateFlags can check them once they have been parsed.
+
+var (
+	wide         = flag.Bool("w", false, "32 byte wide display (cannot use with '-x')")
+	extraWide    = flag.Bool("x", false, "64 byte wide display (cannot use with '-w')")
+	width        = flag.Int("width", 0, "display `N` bytes per line (cannot use with '-w' or '-x')")
+	squeeze      = flag.Bool("c", true, "squeeze runs of duplicate lines into a single '*' line (see -v)")
+	verbose      = flag.Bool("v", false, "verbose: print every line, even duplicates (overrides -c)")
+	repeatMarker = flag.String("repeat-marker", "terse", "squeeze line: terse for '*', or verbose for '* (0x1000 bytes identical)' giving the bytes left out")
+	trim         = flag.Bool("trim", false, "collapse runs of all 0x00 or all 0x20 lines into one line giving their length (unlike -c, only padding, and not just repeats)")
+	step         = flag.Int("step", 1, "print only every `N`th line, the first and last lines always")
+
+	record   byteCount
+	readSize sizeFlag
+	skip     byteCount
+	length   byteCount
+	byteSpan byteRange
+	pattern  hexBytes
+	marked   hexBytes
+	needle   hexBytes
+	fromHex  hexBytes
+	blocks   blockList
+	blockLen sizeFlag
+	timeAt   timeField
+
+	reverse      = flag.Bool("r", false, "reverse: convert a dump back into binary")
+	diff         = flag.Bool("diff", false, "show the lines where two files differ")
+	refFile      = flag.String("ref", "", "show only the lines of the input that differ from the reference `file`, with its bytes alongside")
+	compare      = flag.Bool("cmp", false, "only report whether two files differ, and the offset of the first difference, like cmp")
+	quiet        = flag.Bool("quiet", false, "no warnings for skipped files (the exit status is still 1); with -cmp, print only the offset of the first difference")
+	check        = flag.Bool("check", false, "only report whether each file would be dumped, or why it would be skipped")
+	anyFile      = flag.Bool("any", false, "also dump named pipes, devices and sockets, not only regular files")
+	mmapFile     = flag.Bool("mmap", false, "map regular files into memory rather than reading them, so that -skip costs nothing")
+	sparse       = flag.Bool("sparse", false, "show each hole of a sparse file as one marker line instead of its zeros (Linux only)")
+	fromBase64   = flag.String("from-base64", "", "dump the bytes of the base64 `text` given, or read from STDIN for -, instead of files")
+	filesFrom    = flag.String("files-from", "", "also dump the files listed in `file`, one per line (- for STDIN)")
+	filesFrom0   = flag.String("files-from0", "", "also dump the files listed in `file`, each ended by a NUL as from find -print0 (- for STDIN)")
+	recursive    = flag.Bool("recursive", false, "dump every regular file under each directory argument, with a header each")
+	gunzip       = flag.Bool("z", false, "decompress input that starts with the gzip magic number")
+	stats        = flag.Bool("stats", false, "print byte statistics (entropy, frequencies) instead of a dump")
+	count        = flag.Bool("count", false, "print only the number of bytes read, like 'wc -c', instead of a dump")
+	histogram    = flag.Bool("histogram", false, "print a bar chart of how often each byte value is seen instead of a dump")
+	sumAlgorithm = flag.String("sum", "", "print a checksum of each input after its dump: crc32, md5 or sha256")
+	addressRadix = flag.String("A", "x", "offset `radix`: d (decimal), o (octal) or x (hex)")
+	relative     = flag.Bool("relative", false, "count the offsets from where the dump starts (after -skip or -find) rather than from the start of the input")
+	rightOffset  = flag.Bool("right-offset", false, "repeat the offset at the end of each line, as the offset of the next line")
+	lineNumbers  = flag.Bool("lineno", false, "show the number of each line, from 1, instead of its offset (overrides -A)")
+	addrDigits   = flag.Int("addr-digits", 0, "make the offset column `N` digits wide for every input, so that several dumps line up")
+	addressWidth = flag.Int("addr-width", 0, "size of the offset column in `bytes`: 2, 4 or 8 (default from the file size, 4 for STDIN)")
+	upper        = flag.Bool("u", false, "upper case hex for both the offset and the bytes")
+	group        = flag.Int("g", 0, "put an extra space after every `N` bytes of the hex column")
+	binary       = flag.Bool("b", false, "show each byte as 8 binary digits rather than 2 hex digits")
+	byteType     = flag.String("t", "x1", "show each byte as od -t `type` does: x1 (hex), d1 (signed decimal), u1 (unsigned decimal), o1 (octal) or c (character)")
+	word         = flag.Int("word", 1, "show the hex column as words of `SIZE` bytes: 1, 2, 4 or 8")
+	endian       = flag.String("endian", "big", "byte order of the -word words: big or little")
+	grepContext  = flag.Int("context", 0, "print `N` lines either side of each -grep line")
+	summary      = flag.Bool("summary", false, "print the number of bytes and lines after each dump, and a total for several inputs")
+	header       = flag.Bool("header", false, "print a ruler line with the byte index of each hex column above each dump")
+	magic        = flag.Bool("magic", false, "print a guess at the file type, from its magic number, above each dump")
+	dot          = flag.String("dot", ".", "`character` shown in the ASCII column for non printable bytes")
+	latin1       = flag.Bool("latin1", false, "treat bytes 0xA0 to 0xFF as printable Latin-1 characters")
+	ebcdic       = flag.Bool("ebcdic", false, "decode the character column as EBCDIC (code page 037) rather than ASCII")
+	strip7       = flag.Bool("strip7", false, "clear the high (parity) bit of each byte before showing it in the character column")
+	decodeUTF8   = flag.Bool("utf8", false, "decode the character column as UTF-8, with a '_' for each byte of a character after the first")
+	mnemonics    = flag.Bool("mnemonic", false, "name the control characters of each line in an extra column")
+	separator    = flag.String("sep", "", "`text` put between the columns instead of ' : ', with Go escapes such as \\t")
+	noASCII      = flag.Bool("no-ascii", false, "leave out the ASCII column, keeping the offset and hex columns")
+	noOffset     = flag.Bool("no-offset", false, "leave out the offset column, starting each line with the hex bytes")
+	asciiFirst   = flag.Bool("ascii-first", false, "put the ASCII column before the hex column")
+	lineCRC      = flag.Bool("line-crc", false, "add a column with the CRC-16/CCITT-FALSE of each line's bytes")
+	delta        = flag.Bool("delta", false, "add a column with the signed difference between each byte and the byte before it")
+	colorMode    = flag.String("color", "never", "colour the hex bytes: auto, always or never")
+	lineTemplate = flag.String("template", "", "lay out each line with a Go text/template `text` using .Offset, .Hex, .Ascii and .Bytes")
+	outputFormat = flag.String("format", "text", "output `format`: text, json (one object per line), csv or canonical (as hexdump -C)")
+	canonical    = flag.Bool("C", false, "canonical hex+ASCII layout, exactly as hexdump -C (same as -format canonical)")
+	include      = flag.Bool("i", false, "output a C unsigned char array, like 'xxd -i'")
+	plain        = flag.Bool("p", false, "plain hex output with no offset or ASCII columns, like 'xxd -p'")
+	goEscape     = flag.Bool("goescape", false, "output a quoted Go string literal, with \\xNN escapes, split over lines joined with +")
+	cEscape      = flag.Bool("cescape", false, "output a quoted C string literal, with octal escapes, split over lines")
+	plainColumns = flag.Int("cols", hexdump.PlainColumns, "hex digits per line of the -p output, two for each byte")
+	arrayName    = flag.String("name", "", "`name` of the C array for -i (default taken from the file name)")
+	stdinName    = flag.String("stdin-name", "-", "`name` used for STDIN in headers, checksum lines and C array names")
+	crlf         = flag.Bool("crlf", false, "end each line of text output with CRLF rather than LF")
+	outputFile   = flag.String("o", "", "write the output to `file` rather than STDOUT (truncating it)")
+	teeFile      = flag.String("tee", "", "also write the bytes dumped, as they are, to `file` (truncating it)")
+	showProgress = flag.Bool("progress", false, "report the bytes dumped so far on STDERR, a few times a second")
+	jobs         = flag.Int("j", 1, "dump up to `N` files at once, the output is still in argument order")
+	timeout      = flag.Duration("timeout", 30*time.Second, "time limit for fetching an http or https URL")
+)
+
+func init() {
+
+	flag.BoolVar(plain, "plain", false, "same as -p")
+
+	flag.Var(&readSize, "buffer-size", "read the input `N` bytes at a time, such as 64K or 1M, at least the width (default 4096)")
+	flag.Var(&timeAt, "time-at", "show the Unix time at `OFFSET,SIZE,ENDIAN` of each -record, such as 8,4,little")
+	flag.Var(&record, "record", "start every `N` bytes (decimal or 0x hex) on a new line, with a dashed line between the records")
+	flag.Var(&skip, "skip", "skip `N` bytes of input before dumping (decimal or 0x hex)")
+	flag.Var(&skip, "s", "shorthand for -skip")
+	flag.Var(&length, "length", "stop after dumping `N` bytes (decimal or 0x hex)")
+	flag.Var(&length, "n", "shorthand for -length")
+	flag.Var(&blocks, "block", "dump only block `N` of -block-size bytes; give it again for more blocks")
+	flag.Var(&blockLen, "block-size", "size of the -block blocks, `N` bytes such as 512 or 4K")
+	flag.Var(&byteSpan, "range", "only dump the bytes from `START:END` (END exclusive, either can be left out)")
+	flag.Var(&marked, "highlight", "mark every place the `HEX` bytes appear (reverse video with -color, else a line of '^')")
+	flag.Var(&needle, "grep", "only print the lines holding the `HEX` bytes")
+	flag.Var(&fromHex, "from-hex", "dump the `HEX` bytes given, such as 'de ad be ef', instead of files")
+	flag.Var(&pattern, "find", "start the dump at the first place the `HEX` bytes appear (after any -skip)")
+}
+
+// radixes, scales and formats map the values of -A, -addr-width and
+// -format to the hexdump package settings
+
+var radixes = map[string]hexdump.Radix{
+	"x": hexdump.Hex,
+	"d": hexdump.Decimal,
+	"o": hexdump.Octal,
+}
+
+var scales = map[int]hexdump.Scale{
+	2: hexdump.Scale16,
+	4: hexdump.Scale32,
+	8: hexdump.Scale64,
+}
+
+var byteTypes = map[string]hexdump.ByteType{
+	"x1": hexdump.HexByte,
+	"d1": hexdump.SignedByte,
+	"u1": hexdump.UnsignedByte,
+	"o1": hexdump.OctalByte,
+	"c":  hexdump.CharByte,
+}
+
+var formats = map[string]hexdump.Format{
+	"text":      hexdump.Text,
+	"json":      hexdump.JSON,
+	"csv":       hexdump.CSV,
+	"canonical": hexdump.Canonical,
+}
+
+func main() {
+
+	flag.Parse()
+	args := flag.Args()
+
+	if *canonical && !isFlagSet("format") {
+		*outputFormat = "canonical"
+	}
+
+	if err := validateFlags(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+
+	// The template is parsed before any input is read, so a mistake
+	// in it is reported straight away
+	var layout *template.Template
+	if *lineTemplate != "" {
+		var err error
+		if layout, err = template.New("-template").Parse(*lineTemplate); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// The files listed by -files-from, then -files-from0, go after those
+	// on the command line
+	if *filesFrom != "" {
+		listed, err := readFileList(*filesFrom, false)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Cannot read the -files-from list: %s\n", err)
+			os.Exit(1)
+		}
+		args = append(args, listed...)
+	}
+	if *filesFrom0 != "" {
+		listed, err := readFileList(*filesFrom0, true)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Cannot read the -files-from0 list: %s\n", err)
+			os.Exit(1)
+		}
+		args = append(args, listed...)
+	}
+
+	if isFlagSet("range") {
+		// An empty range has nothing to dump
+		if byteSpan.hasEnd && byteSpan.end == byteSpan.start {
+			return
+		}
+
+		skip = byteCount(byteSpan.start)
+		if byteSpan.hasEnd {
+			length = byteCount(byteSpan.end - byteSpan.start)
+		}
+	}
+
+	// Squeezing is the default, as with BSD hexdump, so -v wins over -c
+	if *verbose {
+		*squeeze = false
+	}
+
+	dotRune, _ := utf8.DecodeRuneInString(*dot)
+	columnSeparator, _ := strconv.Unquote(`"` + *separator + `"`)
+
+	var color bool
+	switch *colorMode {
+	case "always":
+		color = true
+	case "auto":
+		color = *outputFile == "" && isTerminal(os.Stdout)
+	}
+
+	var displayWidth int
+	switch {
+	case isFlagSet("width"):
+		displayWidth = *width
+	case *wide:
+		displayWidth = hexdump.WideWidth
+	case *extraWide:
+		displayWidth = hexdump.ExtraWideWidth
+	default:
+		displayWidth = hexdump.NormalWidth
+	}
+
+	out := os.Stdout
+	if *outputFile != "" {
+		var err error
+		if out, err = os.Create(*outputFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Cannot create the output file: %s\n", err)
+			os.Exit(1)
+		}
+		defer out.Close()
+	}
+
+	// -recursive replaces each directory with the regular files in it
+	walked := true
+	if *recursive {
+		args, walked = walkDirectories(args)
+	}
+
+	// Only -r writes binary, every other output is text whose lines
+	// -crlf can end in CRLF
+	var text io.Writer = out
+	if *crlf {
+		text = crlfWriter{out}
+	}
+
+	if *check {
+		if !checkFiles(text, args) || !walked {
+			out.Close()
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *reverse {
+		if !reverseDump(out, args, *gunzip) {
+			out.Close()
+			os.Exit(1)
+		}
+		return
+	}
+
+	opts := hexdump.Options{
+		Format:       formats[*outputFormat],
+		Width:        displayWidth,
+		Radix:        radixes[*addressRadix],
+		OffsetDigits: *addrDigits,
+		LineNumbers:  *lineNumbers,
+		RightOffset:  *rightOffset,
+		ASCIIFirst:   *asciiFirst,
+		LineCRC:      *lineCRC,
+		Delta:        *delta,
+		Upper:        *upper,
+		Group:        *group,
+		Word:         *word,
+		LittleEndian: *endian == "little",
+		Binary:       *binary,
+		Type:         byteTypes[*byteType],
+		Color:        color,
+		Dot:          dotRune,
+		Latin1:       *latin1,
+		EBCDIC:       *ebcdic,
+		Strip7:       *strip7,
+		UTF8:         *decodeUTF8,
+		Mnemonic:     *mnemonics,
+		NoASCII:      *noASCII,
+		NoOffset:     *noOffset,
+		Separator:    columnSeparator,
+		Template:     layout,
+		Squeeze:      *squeeze,
+		SqueezeCount: *repeatMarker == "verbose",
+		Trim:         *trim,
+		Step:         *step,
+		Record:       uint64(record),
+		BufferSize:   int(readSize),
+		Header:       *header,
+		Magic:        *magic,
+		Skip:         uint64(skip),
+		Relative:     *relative,
+		Find:         pattern,
+		Highlight:    marked,
+		Grep:         needle,
+		Context:      *grepContext,
+		Length:       uint64(length),
+	}
+	if timeAt.set {
+		opts.TimeAt = &timeAt.TimeField
+	}
+
+	if *diff {
+		diffFiles(text, args, opts, *gunzip)
+		return
+	}
+
+	if *refFile != "" {
+		diffReference(text, *refFile, args, opts, *gunzip)
+		return
+	}
+
+	if *compare {
+		if !compareFiles(text, args, opts, *gunzip) {
+			out.Close()
+			os.Exit(1)
+		}
+		return
+	}
+
+	// The bytes of every input go into the one -tee file, one after
+	// the other
+	var tee *os.File
+	if *teeFile != "" {
+		var err error
+		if tee, err = os.Create(*teeFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Cannot create the -tee file: %s\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// Ctrl-C cancels ctx so the dump stops between reads and what has
+	// been formatted is flushed. Once it has, a second Ctrl-C kills the
+	// program as usual, in case it is stuck in a read.
+	ctx, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stopSignals()
+	go func() {
+		<-ctx.Done()
+		stopSignals()
+	}()
+
+	// dump writes one stream to w in the chosen output format. The
+	// label is the file name, or the -stdin-name for STDIN. Each call
+	// has its own checksum so that inputs can be dumped at once. data,
+	// if not nil, is the whole stream mapped into memory, which the
+	// text dump uses in place of r.
+	dump := func(w io.Writer, r io.Reader, data []byte, label string, opts hexdump.Options) error {
+		var sum hash.Hash
+		var tees []io.Writer
+		if *sumAlgorithm != "" {
+			sum = newHash(*sumAlgorithm)
+			tees = append(tees, sum)
+		}
+		if tee != nil {
+			tees = append(tees, tee)
+		}
+		if len(tees) > 0 {
+			opts.Tee = io.MultiWriter(tees...)
+		}
+		r = withSeeker(contextReader{ctx: ctx, r: r}, r)
+
+		var err error
+		switch {
+		case *stats:
+			err = hexdump.Statistics(w, r, opts)
+		case *histogram:
+			err = hexdump.Histogram(w, r, terminalWidth(), opts)
+		case *count:
+			var n uint64
+			if n, err = hexdump.Count(r, opts); err == nil {
+				fmt.Fprintln(w, n)
+			}
+		case *plain:
+			err = hexdump.Plain(w, r, *plainColumns, opts)
+		case *include:
+			name := *arrayName
+			if name == "" && label == "-" {
+				name = "stdin"
+			} else if name == "" {
+				name = cIdentifier(filepath.Base(label))
+			}
+			err = hexdump.CArray(w, r, name, opts)
+		case *goEscape:
+			err = hexdump.GoString(w, r, opts)
+		case *cEscape:
+			err = hexdump.CString(w, r, opts)
+		case data != nil:
+			err = hexdump.DumpBytesContext(ctx, w, data, opts)
+		default:
+			err = hexdump.DumpContext(ctx, w, r, opts)
+		}
+
+		if err == nil && *summary {
+			fmt.Fprintf(w, "%d bytes, %d lines\n", opts.Totals.Bytes, opts.Totals.Lines)
+		}
+		if err == nil && sum != nil {
+			fmt.Fprintf(w, "%s (%s) = %x\n", strings.ToUpper(*sumAlgorithm), label, sum.Sum(nil))
+		}
+		return err
+	}
+
+	// The bytes given with -from-hex or -from-base64 are dumped as the
+	// only input
+	var inline []byte
+	var inlineLabel string
+	switch {
+	case isFlagSet("from-hex"):
+		inline, inlineLabel = fromHex, "hex"
+	case isFlagSet("from-base64"):
+		var err error
+		if inline, err = readBase64(*fromBase64); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: -from-base64: %s\n", err)
+			os.Exit(1)
+		}
+		inlineLabel = "base64"
+	}
+
+	// With no arguments STDIN is dumped, as if "-" had been given. An
+	// empty -files-from or -files-from0 list dumps nothing.
+	switch {
+	case inlineLabel != "":
+		args = []string{inlineInput}
+	case flag.NArg() == 0 && *filesFrom == "" && *filesFrom0 == "":
+		args = []string{"-"}
+	}
+
+	headers := (len(args) > 1 || *recursive) && (opts.Format == hexdump.Text && !*include && !*plain && !*goEscape && !*cEscape || *stats || *histogram || *count)
+
+	// dumpInput opens and dumps one input, writing the dump to w and
+	// any warning to errw. blank asks for an empty line before the
+	// header, to separate it from the dump before.
+	dumpInput := func(file string, w io.Writer, errw io.Writer, blank bool) (res inputResult) {
+
+		// label names the input in headers and checksum lines, name
+		// in warnings
+		label, name := file, file
+
+		var fh io.ReadCloser
+		var fileScale hexdump.Scale
+		var extents []extent
+		var size uint64
+		holes := false
+		var err error
+		switch {
+		case file == inlineInput:
+			label, name = inlineLabel, "-from-"+inlineLabel
+			fh, fileScale = io.NopCloser(bytes.NewReader(inline)), sizeScale(int64(len(inline)))
+		case file == "-":
+			label, name = *stdinName, "STDIN"
+			fh, fileScale, err = openStdin(*gunzip)
+		case isURL(file):
+			fh, fileScale, err = openURL(file, *gunzip, *timeout)
+		default:
+			fh, fileScale, err = openRegularFile(file, *gunzip)
+			if err == nil && *sparse {
+				extents, size, holes = dataExtents(file)
+			}
+		}
+
+		if err != nil {
+			warnSkipped(errw, "file", err)
+			res.failed = true
+			return
+		}
+		defer fh.Close()
+
+		if headers {
+			if blank {
+				fmt.Fprintln(w)
+			}
+			fmt.Fprintf(w, "==> %s <==\n", label)
+			res.header = true
+		}
+
+		_, compressed := fh.(gzipFile)
+
+		opts := opts
+		opts.Scale = addressScale(fileScale)
+		opts.Totals = &res.totals
+
+		// A file too big for the -addr-digits gets as many digits as
+		// it needs, for the whole file so that its lines still line up
+		if need := offsetDigits(file, compressed, opts); *addrDigits > 0 && need > *addrDigits {
+			fmt.Fprintf(errw, "Warning: %s needs %d digit offsets, more than -addr-digits %d\n", name, need, *addrDigits)
+			opts.OffsetDigits = need
+		}
+
+		// A mapped file is dumped straight from the mapping, unless
+		// -progress has to count its reads
+		var mapped []byte
+		if m, ok := fh.(*mappedFile); ok && !*showProgress {
+			mapped = m.data
+		}
+
+		var r io.Reader = fh
+		if *showProgress {
+			p := startProgress(os.Stderr, label, expectedSize(file, compressed, opts))
+			r = withSeeker(p.reader(fh), fh)
+			defer p.finish()
+		}
+
+		// Only a dump cut short by the input is marked as such, not one
+		// stopped by a template or write error
+		r = withSeeker(errorReader{r: r}, r)
+
+		switch {
+		case len(blocks) > 0:
+			err = dumpBlocks(w, r, blocks, uint64(blockLen), opts, func(r io.Reader, opts hexdump.Options) error {
+				return dump(w, r, mapped, label, opts)
+			})
+		case holes:
+			err = dumpSparse(ctx, w, r, extents, size, opts)
+		default:
+			err = dump(w, r, mapped, label, opts)
+		}
+		if err == nil {
+			return
+		}
+
+		var pastEnd blockPastEnd
+		var readErr readError
+		res.failed = true
+		switch {
+		case errors.Is(err, context.Canceled):
+			res.exit = 130
+		case errors.As(err, &pastEnd):
+			fmt.Fprintf(errw, "Error: Block %d is past the end of %s\n", pastEnd.block, name)
+			res.exit = 1
+		case err == errNoSeek:
+			fmt.Fprintf(errw, "Error: %s, not %s\n", err, name)
+			res.exit = 1
+		case err == hexdump.ErrNotFound:
+			fmt.Fprintf(errw, "\nWarning: %s: -find pattern not found\n", name)
+		case err == hexdump.ErrSkipPastEnd:
+			fmt.Fprintf(errw, "Error: Skip offset %d is past the end of %s\n", skip, name)
+			res.exit = 1
+		case errors.As(err, &readErr) && compressed:
+			fmt.Fprintln(w, readErrorMarker)
+			fmt.Fprintf(errw, "\nWarning: %s: corrupt gzip data: %s\n", name, err)
+		case errors.As(err, &readErr):
+			fmt.Fprintln(w, readErrorMarker)
+			fmt.Fprintln(errw, "Error:", err)
+		default:
+			fmt.Fprintln(errw, "Error:", err)
+		}
+		return
+	}
+
+	// failed is set when an input is skipped or cannot be fully dumped
+	failed := !walked
+	headerPrinted := false
+
+	// total adds up the -summary counts of every input
+	var total hexdump.Totals
+
+	// done takes the result of each input, in argument order, stopping
+	// the program if the input asks for it
+	done := func(res inputResult) {
+		failed = failed || res.failed
+		headerPrinted = headerPrinted || res.header
+		total.Bytes += res.totals.Bytes
+		total.Lines += res.totals.Lines
+		if res.exit != 0 {
+			out.Close()
+			os.Exit(res.exit)
+		}
+	}
+
+	if *jobs <= 1 || len(args) == 1 {
+		for _, file := range args {
+			done(dumpInput(file, text, os.Stderr, headerPrinted))
+		}
+	} else {
+		// Each input is dumped into a buffer of its own and the buffers
+		// are written in argument order. At most -j inputs are being
+		// dumped or waiting to be written at any time.
+		outputs := make([]chan *bufferedInput, len(args))
+		for i := range outputs {
+			outputs[i] = make(chan *bufferedInput, 1)
+		}
+		slots := make(chan struct{}, *jobs)
+
+		go func() {
+			for i, file := range args {
+				slots <- struct{}{}
+				go func() {
+					input := &bufferedInput{}
+					input.result = dumpInput(file, &input.dump, &input.warnings, false)
+					outputs[i] <- input
+				}()
+			}
+		}()
+
+		for i := range args {
+			input := <-outputs[i]
+			if headerPrinted && input.result.header {
+				fmt.Fprintln(text)
+			}
+			text.Write(input.dump.Bytes())
+			os.Stderr.Write(input.warnings.Bytes())
+			<-slots
+			done(input.result)
+		}
+	}
+
+	if *summary && len(args) > 1 {
+		fmt.Fprintf(text, "\n%d bytes, %d lines in total\n", total.Bytes, total.Lines)
+	}
+
+	if tee != nil {
+		if err := tee.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Cannot write the -tee file: %s\n", err)
+			failed = true
+		}
+	}
+
+	// os.Exit skips the deferred calls, so the output is closed here
+	if failed {
+		out.Close()
+		os.Exit(1)
+	}
+}
+
+// validateFlags checks the parsed command line flags, returning an
+// 		error for any value that is out of range or any combination of
+// 		flags that cannot be used together
+
+func validateFlags() error {
+
+	if *wide && *extraWide {
+		return errors.New("Wide and Extra wide options are mutually exclusive")
+	}
+
+	if isFlagSet("width") {
+		if *wide || *extraWide {
+			return errors.New("-width cannot be used with -w or -x")
+		}
+		if *width < 1 {
+			return errors.New("The width must be at least 1 byte")
+		}
+		if *width > hexdump.MaxWidth {
+			return fmt.Errorf("The width must be at most %d bytes", hexdump.MaxWidth)
+		}
+	}
+
+	lineWidth := hexdump.NormalWidth
+	switch {
+	case isFlagSet("width"):
+		lineWidth = *width
+	case *wide:
+		lineWidth = hexdump.WideWidth
+	case *extraWide:
+		lineWidth = hexdump.ExtraWideWidth
+	}
+	if isFlagSet("buffer-size") {
+		if readSize < sizeFlag(lineWidth) {
+			return fmt.Errorf("The buffer size must be at least the width, %d bytes", lineWidth)
+		}
+		if readSize > maxBufferSize {
+			return errors.New("The buffer size must be at most 1G")
+		}
+	}
+
+	// A Length of 0 means no limit, so -length 0 would dump it all
+	if (isFlagSet("length") || isFlagSet("n")) && length == 0 {
+		return errors.New("-length must be at least 1 byte")
+	}
+
+	if isFlagSet("range") && (isFlagSet("skip") || isFlagSet("s") || isFlagSet("length") || isFlagSet("n")) {
+		return errors.New("-range cannot be used with -skip or -length")
+	}
+
+	if len(blocks) > 0 || isFlagSet("block-size") {
+		if len(blocks) == 0 || blockLen == 0 {
+			return errors.New("-block and a -block-size of at least 1 byte must be used together")
+		}
+		for _, block := range blocks {
+			if block > ^uint64(0)/uint64(blockLen) {
+				return fmt.Errorf("Block %d is too far into the input", block)
+			}
+		}
+		if isFlagSet("skip") || isFlagSet("s") || isFlagSet("length") || isFlagSet("n") || isFlagSet("range") || pattern != nil || *relative || *sparse || *gunzip {
+			return errors.New("-block cannot be used with -skip, -length, -range, -find, -relative, -sparse or -z")
+		}
+		if *diff || *refFile != "" || *compare || *reverse || *check {
+			return errors.New("-block cannot be used with -diff, -ref, -cmp, -r or -check")
+		}
+	}
+
+	if *filesFrom == "-" && *filesFrom0 == "-" {
+		return errors.New("-files-from and -files-from0 cannot both read STDIN")
+	}
+
+	if isFlagSet("from-hex") && isFlagSet("from-base64") {
+		return errors.New("-from-hex cannot be used with -from-base64")
+	}
+
+	for _, from := range []string{"from-hex", "from-base64"} {
+		if isFlagSet(from) && (flag.NArg() > 0 || *filesFrom != "" || *filesFrom0 != "" || *recursive || *reverse || *diff || *compare || *check) {
+			return fmt.Errorf("-%s cannot be used with files, -files-from, -files-from0, -recursive, -r, -diff, -cmp or -check", from)
+		}
+	}
+
+	if *refFile != "" && (*diff || *compare || *reverse || *check || *recursive || *relative) {
+		return errors.New("-ref cannot be used with -diff, -cmp, -r, -check, -recursive or -relative")
+	}
+
+	if *recursive && (*reverse || *diff) {
+		return errors.New("-recursive cannot be used with -r or -diff")
+	}
+
+	if *crlf && *reverse {
+		return errors.New("-crlf cannot be used with -r, which writes binary")
+	}
+
+	if *compare && (*diff || *reverse) {
+		return errors.New("-cmp cannot be used with -diff or -r")
+	}
+
+	if *lineTemplate != "" && (*outputFormat != "text" || *stats || *histogram || *count || *include || *plain || *goEscape || *cEscape) {
+		return errors.New("-template can only be used with the text output")
+	}
+
+	if *summary && (*outputFormat != "text" || *stats || *histogram || *count || *include || *plain || *goEscape || *cEscape || *diff || *compare || *reverse || *check) {
+		return errors.New("-summary can only be used with the text output")
+	}
+
+	if *sparse && (*outputFormat != "text" || *stats || *histogram || *count || *include || *plain || *goEscape || *cEscape) {
+		return errors.New("-sparse can only be used with the text output")
+	}
+
+	if *sparse && (*diff || *refFile != "" || *compare || *reverse || *gunzip || pattern != nil || *relative || *lineNumbers || *summary || *sumAlgorithm != "" || *teeFile != "") {
+		return errors.New("-sparse cannot be used with -diff, -ref, -cmp, -r, -z, -find, -relative, -lineno, -summary, -sum or -tee")
+	}
+
+	if *diff && pattern != nil {
+		return errors.New("-find cannot be used with -diff")
+	}
+
+	if *repeatMarker != "terse" && *repeatMarker != "verbose" {
+		return fmt.Errorf("Unknown repeat marker '%s', use terse or verbose", *repeatMarker)
+	}
+
+	if *grepContext < 0 {
+		return errors.New("The -context line count cannot be negative")
+	}
+
+	if *group < 0 {
+		return errors.New("The group size cannot be negative")
+	}
+
+	switch *word {
+	case 1, 2, 4, 8:
+	default:
+		return fmt.Errorf("Unknown word size %d, use 1, 2, 4 or 8", *word)
+	}
+
+	if *endian != "big" && *endian != "little" {
+		return fmt.Errorf("Unknown endian '%s', use big or little", *endian)
+	}
+
+	if isFlagSet("width") && *width%*word != 0 {
+		return errors.New("The width must be a multiple of the word size")
+	}
+
+	if *group%*word != 0 {
+		return errors.New("The group size must be a multiple of the word size")
+	}
+
+	outputs := 0
+	for _, set := range []bool{*stats, *histogram, *count, *include, *plain, *goEscape, *cEscape} {
+		if set {
+			outputs++
+		}
+	}
+	if outputs > 1 {
+		return errors.New("Only one of -stats, -histogram, -count, -i, -p, -goescape and -cescape can be used")
+	}
+
+	if *jobs < 1 {
+		return errors.New("-j must be at least 1")
+	}
+
+	if *step < 1 {
+		return errors.New("-step must be at least 1")
+	}
+
+	if *step > 1 && *trim {
+		return errors.New("-trim cannot be used with -step")
+	}
+
+	if timeAt.set {
+		if record == 0 {
+			return errors.New("-time-at needs -record")
+		}
+		if uint64(timeAt.Size) > uint64(record) || timeAt.Offset > uint64(record)-uint64(timeAt.Size) {
+			return fmt.Errorf("The -time-at field must fit in a %d byte record", record)
+		}
+		if marked != nil || needle != nil || *step > 1 {
+			return errors.New("-time-at cannot be used with -highlight, -grep or -step")
+		}
+	}
+
+	if record > 0 && (*lineNumbers || *outputFormat == "canonical") {
+		return errors.New("-record cannot be used with -lineno or -C")
+	}
+
+	if *teeFile != "" && (*jobs > 1 || *reverse || *diff || *check) {
+		return errors.New("-tee cannot be used with -j, -r, -diff or -check")
+	}
+
+	if *showProgress && *jobs > 1 {
+		return errors.New("-progress cannot be used with -j")
+	}
+
+	if *plainColumns < 2 || *plainColumns%2 != 0 {
+		return errors.New("-cols must be an even number of hex digits, two for each byte")
+	}
+
+	if *noASCII && *mnemonics {
+		return errors.New("-mnemonic cannot be used with -no-ascii")
+	}
+
+	if *delta && (marked != nil || needle != nil || *step > 1 || *reverse) {
+		return errors.New("-delta cannot be used with -highlight, -grep, -step or -r")
+	}
+
+	if *noOffset && *reverse {
+		return errors.New("-no-offset cannot be used with -r")
+	}
+
+	if *asciiFirst && (*noASCII || *reverse) {
+		return errors.New("-ascii-first cannot be used with -no-ascii or -r")
+	}
+
+	if *ebcdic && (*latin1 || *mnemonics) {
+		return errors.New("-ebcdic cannot be used with -latin1 or -mnemonic")
+	}
+
+	if *strip7 && (*ebcdic || *latin1) {
+		return errors.New("-strip7 cannot be used with -ebcdic or -latin1")
+	}
+
+	if *decodeUTF8 && (*ebcdic || *latin1 || *strip7) {
+		return errors.New("-utf8 cannot be used with -ebcdic, -latin1 or -strip7")
+	}
+
+	if _, ok := radixes[*addressRadix]; !ok {
+		return fmt.Errorf("Unknown address radix '%s', use d, o or x", *addressRadix)
+	}
+
+	if *addrDigits < 0 || *addrDigits > 22 {
+		return errors.New("-addr-digits must be from 1 to 22")
+	}
+
+	if *addrDigits > 0 && isFlagSet("addr-width") {
+		return errors.New("-addr-digits cannot be used with -addr-width")
+	}
+
+	if _, ok := scales[*addressWidth]; isFlagSet("addr-width") && !ok {
+		return fmt.Errorf("Unknown address width %d, use 2, 4 or 8", *addressWidth)
+	}
+
+	if _, err := strconv.Unquote(`"` + *separator + `"`); err != nil {
+		return fmt.Errorf("Cannot read the -sep text '%s': %s", *separator, err)
+	}
+
+	if *reverse && isFlagSet("sep") {
+		return errors.New("-sep cannot be used with -r, which only reads ' : ' separators")
+	}
+
+	if utf8.RuneCountInString(*dot) != 1 || !utf8.ValidString(*dot) {
+		return errors.New("-dot must be a single character")
+	}
+
+	switch *colorMode {
+	case "auto", "always", "never":
+	default:
+		return fmt.Errorf("Unknown colour mode '%s', use auto, always or never", *colorMode)
+	}
+
+	if _, ok := byteTypes[*byteType]; !ok {
+		return fmt.Errorf("Unknown type '%s', use x1, d1, u1, o1 or c", *byteType)
+	}
+
+	if *byteType != "x1" && (*binary || *word != 1) {
+		return errors.New("-t cannot be used with -b or -word")
+	}
+
+	if _, ok := formats[*outputFormat]; !ok {
+		return fmt.Errorf("Unknown output format '%s', use text, json, csv or canonical", *outputFormat)
+	}
+
+	if *canonical && *outputFormat != "canonical" {
+		return errors.New("-C cannot be used with another -format")
+	}
+
+	if *sumAlgorithm != "" && newHash(*sumAlgorithm) == nil {
+		return fmt.Errorf("Unknown checksum '%s', use crc32, md5 or sha256", *sumAlgorithm)
+	}
+
+	return nil
+}
+
+// isFlagSet reports whether the named flag was given on the command line
+
+func isFlagSet(name string) bool {
+
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+
+	return set
+}
+
+// diffFiles writes the lines where two files differ to w. The offset
+// column is sized for the larger of the two files. Either file, but
+// not both, can be "-" for STDIN.
+
+func diffFiles(w io.Writer, files []string, opts hexdump.Options, gunzip bool) {
+
+	if len(files) != 2 {
+		fmt.Fprintf(os.Stderr, "Error: -diff needs exactly two files\n")
+		os.Exit(1)
+	}
+
+	if files[0] == "-" && files[1] == "-" {
+		fmt.Fprintf(os.Stderr, "Error: -diff can only read one of the files from STDIN\n")
+		os.Exit(1)
+	}
+
+	fileA, scaleA, err := openInput(files[0], gunzip)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+	defer fileA.Close()
+
+	fileB, scaleB, err := openInput(files[1], gunzip)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+	defer fileB.Close()
+
+	opts.Scale = scaleA
+	if scaleA != hexdump.Scale64 && (scaleB == hexdump.Scale64 || scaleB == hexdump.Scale32) {
+		opts.Scale = scaleB
+	}
+	opts.Scale = addressScale(opts.Scale)
+
+	if err := hexdump.Diff(w, fileA, fileB, opts); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
+
+// diffReference writes the lines of the input that differ from the
+// 		reference file to w, with the reference bytes alongside. The
+// 		input is the one file given, or STDIN if there is none. The
+// 		offset column is sized for the input.
+
+func diffReference(w io.Writer, ref string, files []string, opts hexdump.Options, gunzip bool) {
+
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	if len(files) != 1 {
+		fmt.Fprintf(os.Stderr, "Error: -ref needs one file to check against it, or none for STDIN\n")
+		os.Exit(1)
+	}
+
+	if files[0] == "-" && ref == "-" {
+		fmt.Fprintf(os.Stderr, "Error: -ref can only read one of the files from STDIN\n")
+		os.Exit(1)
+	}
+
+	file, scale, err := openInput(files[0], gunzip)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+	defer file.Close()
+
+	reference, _, err := openInput(ref, gunzip)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+	defer reference.Close()
+
+	opts.Scale = addressScale(scale)
+
+	if err := hexdump.DiffReference(w, file, reference, opts); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
+
+// compareFiles reports whether two files are the same and, if not,
+// 		the offset of the first byte that differs, returning false if
+// 		they differ. With -quiet only the offset is printed, and
+// 		nothing for identical files, for use in scripts.
+
+func compareFiles(w io.Writer, files []string, opts hexdump.Options, gunzip bool) bool {
+
+	if len(files) != 2 {
+		fmt.Fprintf(os.Stderr, "Error: -cmp needs exactly two files\n")
+		os.Exit(1)
+	}
+
+	if files[0] == "-" && files[1] == "-" {
+		fmt.Fprintf(os.Stderr, "Error: -cmp can only read one of the files from STDIN\n")
+		os.Exit(1)
+	}
+
+	fileA, _, err := openInput(files[0], gunzip)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+	defer fileA.Close()
+
+	fileB, _, err := openInput(files[1], gunzip)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+	defer fileB.Close()
+
+	offset, same, err := hexdump.Compare(fileA, fileB, opts)
+	switch {
+	case err != nil:
+		fmt.Fprintln(os.Stderr, "Error:", err)
+	case same && !*quiet:
+		fmt.Fprintln(w, "identical")
+	case same:
+	case *quiet:
+		fmt.Fprintln(w, offset)
+	default:
+		fmt.Fprintf(w, "%s %s differ: offset %d (0x%x)\n", files[0], files[1], offset, offset)
+	}
+
+	return err == nil && same
+}
+
+// reverseDump converts the dumps read from STDIN, or from the given
+// 		files, back into binary written to w. It returns false if a
+// 		file was skipped or could not be read.
+
+func reverseDump(w io.Writer, files []string, gunzip bool) bool {
+
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	ok := true
+	for _, file := range files {
+		if fh, _, err := openInput(file, gunzip); err != nil {
+			warnSkipped(os.Stderr, "file", err)
+			ok = false
+		} else {
+			err = hexdump.ReverseRadix(w, fh, radixes[*addressRadix])
+			fh.Close()
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "Error:", err)
+				ok = false
+			}
+		}
+	}
+
+	return ok
+}
+
+// warnSkipped writes the warning for a file or directory that is
+// 		skipped to w, unless -quiet is set. The read errors of an input
+// 		that was opened are not skip warnings and are always written.
+
+func warnSkipped(w io.Writer, what string, err error) {
+
+	if !*quiet {
+		fmt.Fprintf(w, "\nWarning: Skipping %s: %s\n", what, err)
+	}
+}
+
+// inputResult is the outcome of dumping one input. failed is set if it
+// 		was skipped or could not be fully dumped, and exit is the status
+// 		to stop the program with once it is written (0 to carry on).
+
+type inputResult struct {
+	header bool // a "==>" header was printed
+	failed bool
+	exit   int
+	totals hexdump.Totals // the -summary counts
+}
+
+// bufferedInput holds the output of an input dumped by -j until it can
+// be written in its turn
+
+type bufferedInput struct {
+	dump     bytes.Buffer
+	warnings bytes.Buffer
+	result   inputResult
+}
+
+// walkDirectories returns the files with each directory replaced by
+// 		the regular files under it, in lexical order. Symbolic links
+// 		to files are kept, but links to directories are not followed,
+// 		so a link loop cannot make the walk go round for ever. A
+// 		directory that cannot be read gets a warning and ok is false.
+
+func walkDirectories(files []string) (walked []string, ok bool) {
+
+	ok = true
+	for _, file := range files {
+		if info, err := os.Stat(file); err != nil || !info.IsDir() {
+			walked = append(walked, file)
+			continue
+		}
+
+		filepath.WalkDir(file, func(path string, entry fs.DirEntry, err error) error {
+			switch {
+			case err != nil:
+				warnSkipped(os.Stderr, "directory", err)
+				ok = false
+			case entry.Type().IsRegular():
+				walked = append(walked, path)
+			case entry.Type()&fs.ModeSymlink != 0:
+				if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+					walked = append(walked, path)
+				}
+			}
+			return nil
+		})
+	}
+
+	return walked, ok
+}
+
+// checkFiles writes a line to w for each file saying whether it would
+// 		be dumped or, if not, why it would be skipped. The files are
+// 		opened but not read. "-", URLs and, with -any, named pipes
+// 		are always taken as fine, as checking them would mean
+// 		fetching them or waiting for a writer. It returns false if
+// 		any file would be skipped.
+
+func checkFiles(w io.Writer, files []string) bool {
+
+	ok := true
+	for _, file := range files {
+		if file == "-" || isURL(file) {
+			fmt.Fprintf(w, "%s: ok\n", file)
+			continue
+		}
+
+		// Opening a named pipe waits for something to write to it
+		if info, err := os.Stat(file); err == nil && *anyFile && info.Mode()&fs.ModeNamedPipe != 0 {
+			fmt.Fprintf(w, "%s: ok\n", file)
+			continue
+		}
+
+		fh, _, err := openRegularFile(file, false)
+		if err == nil {
+			fh.Close()
+			fmt.Fprintf(w, "%s: ok\n", file)
+			continue
+		}
+
+		reason := err.Error()
+		var skipped fileError
+		if errors.As(err, &skipped) {
+			reason = skipped.reason
+		}
+		fmt.Fprintf(w, "%s: skip: %s\n", file, reason)
+		ok = false
+	}
+
+	return ok
+}
+
+// contextReader is a reader that fails with the context's error once
+// the context is cancelled, so that the outputs that do not take a
+// context (-stats, -i) stop too
+
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c contextReader) Read(p []byte) (int, error) {
+
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
+
+// readError is an error from reading the input, rather than from
+// laying out or writing the dump
+
+type readError struct {
+	err error
+}
+
+func (e readError) Error() string {
+
+	return e.err.Error()
+}
+
+func (e readError) Unwrap() error {
+
+	return e.err
+}
+
+// errorReader returns the errors reading r, other than io.EOF, as
+// readErrors
+
+type errorReader struct {
+	r io.Reader
+}
+
+func (e errorReader) Read(p []byte) (int, error) {
+
+	n, err := e.r.Read(p)
+	if err != nil && err != io.EOF {
+		err = readError{err: err}
+	}
+	return n, err
+}
+
+// readFileList returns the file names listed in the named file, or in
+// 		STDIN for "-", one per line. Blank lines and lines starting
+// 		with "#" are left out. A line is otherwise used as it is,
+// 		spaces included, apart from the "\r" of a CRLF line end.
+//
+// 		With nul set each name ends with a NUL instead, as find
+// 		-print0 writes them, and is used exactly as it is, newlines
+// 		included. Only empty names are left out.
+
+func readFileList(name string, nul bool) ([]string, error) {
+
+	var r io.Reader = os.Stdin
+	if name != "-" {
+		fh, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		defer fh.Close()
+		r = fh
+	}
+
+	var files []string
+	scanner := bufio.NewScanner(r)
+	if nul {
+		scanner.Split(splitNUL)
+		for scanner.Scan() {
+			if scanner.Text() != "" {
+				files = append(files, scanner.Text())
+			}
+		}
+		return files, scanner.Err()
+	}
+
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		files = append(files, line)
+	}
+
+	return files, scanner.Err()
+}
+
+// splitNUL is a bufio.SplitFunc giving the NUL ended names of a
+// -files-from0 list. The last name need not have a NUL after it.
+
+func splitNUL(data []byte, atEOF bool) (advance int, token []byte, err error) {
+
+	if i := bytes.IndexByte(data, 0); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+
+	return 0, nil, nil
+}
+
+// readBase64 decodes the base64 text, or the text read from STDIN for
+// 		"-". Spaces and line breaks are ignored, as are the "=" padding
+// 		characters, so that a blob wrapped at 76 columns or copied
+// 		from a URL decodes. The URL safe alphabet is used if the text
+// 		holds a "-" or "_", otherwise the standard one.
+
+func readBase64(text string) ([]byte, error) {
+
+	if text == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, err
+		}
+		text = string(data)
+	}
+
+	text = strings.Join(strings.Fields(text), "")
+	text = strings.TrimRight(text, "=")
+
+	encoding := base64.RawStdEncoding
+	if strings.ContainsAny(text, "-_") {
+		encoding = base64.RawURLEncoding
+	}
+
+	return encoding.DecodeString(text)
+}
+
+// crlfWriter writes to w with each "\n" turned into "\r\n", for -crlf
+
+type crlfWriter struct {
+	w io.Writer
+}
+
+func (c crlfWriter) Write(p []byte) (int, error) {
+
+	if _, err := c.w.Write(bytes.ReplaceAll(p, []byte("\n"), []byte("\r\n"))); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// withSeeker gives the reader wrapped the Seek method of r, if r has
+// 		one, so that a Skip on a file wrapped in another reader is
+// 		still done with a seek
+
+func withSeeker(wrapped io.Reader, r io.Reader) io.Reader {
+
+	if seeker, ok := r.(io.Seeker); ok {
+		return struct {
+			io.Reader
+			io.Seeker
+		}{wrapped, seeker}
+	}
+
+	return wrapped
+}
+
+// offsetDigits returns the number of digits the largest offset (or line
+// 		number) of a file's dump takes in the offset column, or 0 if
+// 		the size of the file is not known
+
+func offsetDigits(file string, compressed bool, opts hexdump.Options) int {
+
+	size := expectedSize(file, compressed, opts)
+	if size == 0 {
+		return 0
+	}
+
+	largest := uint64(size) - 1
+	if !opts.Relative {
+		largest += opts.Skip
+	}
+
+	base := map[hexdump.Radix]int{hexdump.Hex: 16, hexdump.Decimal: 10, hexdump.Octal: 8}[opts.Radix]
+	if opts.LineNumbers {
+		largest = largest/uint64(max(opts.Width, 1)) + 1
+		base = 10
+	}
+
+	return len(strconv.FormatUint(largest, base))
+}
+
+// expectedSize returns the number of bytes -progress expects to read
+// 		from a file once any Skip has been seeked past, or 0 if the
+// 		size is not known
+
+func expectedSize(file string, compressed bool, opts hexdump.Options) int64 {
+
+	if file == "-" || isURL(file) || compressed {
+		return 0
+	}
+
+	fileInfo, err := os.Stat(file)
+	if err != nil {
+		return 0
+	}
+
+	size := max(fileInfo.Size()-int64(opts.Skip), 0)
+	if opts.Length > 0 && int64(opts.Length) < size {
+		size = int64(opts.Length)
+	}
+	return size
+}
+
+// newHash returns the hash for the -sum algorithm, or nil if the
+// algorithm is not known
+
+func newHash(algorithm string) hash.Hash {
+
+	switch algorithm {
+	case "crc32":
+		return crc32.NewIEEE()
+	case "md5":
+		return md5.New()
+	case "sha256":
+		return sha256.New()
+	default:
+		return nil
+	}
+}
+
+// cIdentifier turns a file name into a C identifier by replacing
+// every character that cannot be used in one with an underscore
+
+func cIdentifier(name string) string {
+
+	identifier := []byte(name)
+	for i, ch := range identifier {
+		switch {
+		case ch >= 'a' && ch <= 'z', ch >= 'A' && ch <= 'Z', ch == '_':
+		case ch >= '0' && ch <= '9' && i > 0:
+		default:
+			identifier[i] = '_'
+		}
+	}
+
+	return string(identifier)
+}
+
+// terminalWidth is the width the -histogram chart is drawn to: $COLUMNS
+// if the shell exports it, otherwise 80
+
+func terminalWidth() int {
+
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+
+	return 80
+}
+
+// isTerminal reports whether the file is a terminal (character device)
+
+func isTerminal(fh *os.File) bool {
+
+	fileInfo, err := fh.Stat()
+	if err != nil {
+		return false
+	}
+
+	return fileInfo.Mode()&os.ModeCharDevice != 0
+}
+
+// openRegularFile will only allow a regular file to be opened for reading.
+// 		The function returns a stream for a requested file only
+// 		if the following conditions are cleared:
+//
+//		1. The file is a regular file (links are allowed to regular files)
+//		   or, with -any, anything but a directory
+//		2. The user has permissions to read the file
+//
+//		If gunzip is set and the file starts with the gzip magic
+//		number the stream is the decompressed content of the file. As
+//		its size is not known it is given a 64bit offset scale.
+//
+//		With -mmap a regular file is read from a memory mapping, if
+//		it can be mapped.
+//
+//		An error is a fileError naming the file and giving the reason
+//		it cannot be dumped, such as "not found".
+
+func openRegularFile(filename string, gunzip bool) (fh io.ReadCloser, fileSizeScale hexdump.Scale, err error) {
+
+	err = nil
+	fh = nil
+
+	fileInfo, err := os.Stat(filename)
+	if err != nil {
+		err = newFileError(filename, err)
+		return
+	}
+
+	fileMode := fileInfo.Mode()
+	if !fileMode.IsRegular() && (!*anyFile || fileMode.IsDir()) {
+		err = newFileError(filename, errNotRegular)
+		return
+	}
+
+	// A pipe or device has no size to go on
+	fileSizeScale = hexdump.Scale64
+	if fileMode.IsRegular() {
+		fileSizeScale = sizeScale(fileInfo.Size())
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		err = newFileError(filename, err)
+		return
+	}
+
+	// Anything that cannot be mapped is streamed as usual
+	var input io.ReadCloser = file
+	if *mmapFile && fileMode.IsRegular() {
+		if mapped, ok := mapFile(file, fileInfo.Size()); ok {
+			input = mapped
+		}
+	}
+
+	if !gunzip {
+		fh = input
+		return
+	}
+
+	r, compressed, err := gunzipReader(input)
+	switch {
+	case err != nil:
+		input.Close()
+		err = newFileError(filename, err)
+	case compressed:
+		fh = gzipFile{Reader: r.(*gzip.Reader), file: input}
+		fileSizeScale = hexdump.Scale64
+	case r == io.Reader(input):
+		fh = input
+	default:
+		fh = struct {
+			io.Reader
+			io.Closer
+		}{r, input}
+	}
+	return
+}
+
+// openInput opens a file for -diff or -r, with "-" meaning STDIN
+
+func openInput(filename string, gunzip bool) (io.ReadCloser, hexdump.Scale, error) {
+
+	if filename == "-" {
+		return openStdin(gunzip)
+	}
+
+	return openRegularFile(filename, gunzip)
+}
+
+// openStdin returns STDIN as an input, decompressed if gunzip is set
+// 		and it starts with the gzip magic number. Closing it does not
+// 		close STDIN. There is no size to go on, and most piped data
+// 		is small, so the offsets are 32bit.
+
+func openStdin(gunzip bool) (fh io.ReadCloser, fileSizeScale hexdump.Scale, err error) {
+
+	fileSizeScale = hexdump.Scale32
+	stdin := io.NopCloser(os.Stdin)
+
+	if !gunzip {
+		fh = stdin
+		return
+	}
+
+	r, compressed, err := gunzipReader(os.Stdin)
+	switch {
+	case err != nil:
+		err = fmt.Errorf("STDIN: %s", err)
+	case compressed:
+		fh = gzipFile{Reader: r.(*gzip.Reader), file: stdin}
+	default:
+		fh = struct {
+			io.Reader
+			io.Closer
+		}{r, stdin}
+	}
+	return
+}
+
+// addressScale returns the -addr-width scale if it was given, otherwise
+// the scale picked for the input
+
+func addressScale(inputScale hexdump.Scale) hexdump.Scale {
+
+	if isFlagSet("addr-width") {
+		return scales[*addressWidth]
+	}
+
+	return inputScale
+}
+
+// sizeScale returns the smallest offset scale that can address every
+// byte of an input of the given size
+
+func sizeScale(size int64) hexdump.Scale {
+
+	switch {
+	case size < int64(maxUint16):
+		return hexdump.Scale16
+	case size < int64(maxUint32):
+		return hexdump.Scale32
+	default:
+		return hexdump.Scale64
+	}
+}
+
+// gzipFile is a decompressed file. Closing it closes both the gzip
+// stream and the file (or response body) under it.
+
+type gzipFile struct {
+	*gzip.Reader
+	file io.Closer
+}
+
+func (g gzipFile) Close() error {
+
+	g.Reader.Close()
+	return g.file.Close()
+}
+
+// gunzipReader checks the start of the stream for the gzip magic
+// 		number. If it is there the stream is wrapped in a gzip
+// 		reader, otherwise the stream is returned unchanged. A stream
+// 		that can seek is put back where it was and returned itself,
+// 		so that a -skip still seeks, where a pipe is given with the
+// 		bytes that were looked at still buffered.
+
+func gunzipReader(r io.Reader) (io.Reader, bool, error) {
+
+	seeker, seekable := r.(io.Seeker)
+	var start int64
+	if seekable {
+		var err error
+		start, err = seeker.Seek(0, io.SeekCurrent)
+		seekable = err == nil
+	}
+
+	buffered := bufio.NewReader(r)
+
+	magic, _ := buffered.Peek(2)
+	if len(magic) < 2 || magic[0] != 0x1f || magic[1] != 0x8b {
+		if seekable {
+			if _, err := seeker.Seek(start, io.SeekStart); err == nil {
+				return r, false, nil
+			}
+		}
+		return buffered, false, nil
+	}
+
+	zr, err := gzip.NewReader(buffered)
+	if err != nil {
+		return nil, true, fmt.Errorf("corrupt gzip data: %s", err)
+	}
+
+	return zr, true, nil
+}
@@ -0,0 +1,59 @@
+//go:build unix
+
+package main
+
+/*
+	The -mmap input, a regular file mapped into memory and read from the
+	mapping rather than with a read for each buffer.
+
+	Edits:
+
+		2026-10-14		lc 		Created for -mmap
+		2026-10-14		lc 		The text dump is cut from the mapping
+
+	Copyright (c) 2020 NOVA Industries Limited
+
+	No warrenty implied or otherwise
+
+*/
+
+import (
+	"bytes"
+	"os"
+	"syscall"
+)
+
+// mappedFile is a file mapped read only into memory. The text dump is
+// 		cut straight from data. Reading it, for the other outputs,
+// 		copies from the mapping, and seeking (for -skip) only moves
+// 		the position. Closing it unmaps the file as well as closing it.
+
+type mappedFile struct {
+	*bytes.Reader
+	data []byte
+	file *os.File
+}
+
+func (m *mappedFile) Close() error {
+
+	syscall.Munmap(m.data)
+	return m.file.Close()
+}
+
+// mapFile maps the open regular file of the given size. ok is false if
+// 		it cannot be mapped, because it is empty or too big to address
+// 		or the mmap failed, and the file should be streamed instead.
+
+func mapFile(file *os.File, size int64) (*mappedFile, bool) {
+
+	if size <= 0 || int64(int(size)) != size {
+		return nil, false
+	}
+
+	data, err := syscall.Mmap(int(file.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, false
+	}
+
+	return &mappedFile{Reader: bytes.NewReader(data), data: data, file: file}, true
+}
@@ -0,0 +1,37 @@
+//go:build !unix
+
+package main
+
+/*
+	The -mmap input where there is no mmap, every file is streamed.
+
+	Edits:
+
+		2026-10-14		lc 		Created for -mmap
+		2026-10-14		lc 		data field, as on unix
+
+	Copyright (c) 2020 NOVA Industries Limited
+
+	No warrenty implied or otherwise
+
+*/
+
+import (
+	"io"
+	"os"
+)
+
+// mappedFile is never made here, and only exists so that openRegularFile
+// 		builds
+
+type mappedFile struct {
+	io.ReadCloser
+	data []byte
+}
+
+// mapFile always gives ok false, so that the file is streamed
+
+func mapFile(file *os.File, size int64) (*mappedFile, bool) {
+
+	return nil, false
+}
@@ -0,0 +1,137 @@
+package main
+
+/*
+	The -progress report of how far through an input the dump is,
+	written to STDERR so that it does not get mixed into the dump.
+
+	Edits:
+
+		2026-10-14		lc 		Created for -progress
+
+	Copyright (c) 2020 NOVA Industries Limited
+
+	No warrenty implied or otherwise
+
+*/
+
+import (
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+const progressInterval = 250 * time.Millisecond
+
+// progress counts the bytes read from an input and reports them every
+// 		progressInterval until it is stopped. total is the number of
+// 		bytes expected, or 0 if it is not known (STDIN, URLs and
+// 		compressed files).
+
+type progress struct {
+	w     io.Writer
+	name  string
+	total int64
+	count atomic.Int64
+	width int // length of the last report, to blank out
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+// startProgress starts reporting on the named input
+
+func startProgress(w io.Writer, name string, total int64) *progress {
+
+	p := &progress{
+		w:     w,
+		name:  name,
+		total: total,
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+
+	go func() {
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+		defer close(p.done)
+
+		for {
+			select {
+			case <-ticker.C:
+				p.report()
+			case <-p.stop:
+				p.report()
+				fmt.Fprintln(p.w)
+				return
+			}
+		}
+	}()
+
+	return p
+}
+
+// reader returns r with every byte read from it counted
+
+func (p *progress) reader(r io.Reader) io.Reader {
+
+	return progressReader{p: p, r: r}
+}
+
+// finish stops the reports, leaving the final count on its own line
+
+func (p *progress) finish() {
+
+	close(p.stop)
+	<-p.done
+}
+
+// report overwrites the report line with the count so far
+
+func (p *progress) report() {
+
+	count := p.count.Load()
+
+	line := fmt.Sprintf("%s: %s", p.name, byteSize(count))
+	if p.total > 0 {
+		percent := min(100*count/p.total, 100)
+		line = fmt.Sprintf("%s of %s (%d%%)", line, byteSize(p.total), percent)
+	}
+
+	fmt.Fprintf(p.w, "\r%-*s", p.width, line)
+	p.width = len(line)
+}
+
+// progressReader is a reader that adds the bytes read to a progress
+// count
+
+type progressReader struct {
+	p *progress
+	r io.Reader
+}
+
+func (pr progressReader) Read(buffer []byte) (int, error) {
+
+	n, err := pr.r.Read(buffer)
+	pr.p.count.Add(int64(n))
+	return n, err
+}
+
+// byteSize gives a byte count in the largest binary unit that keeps it
+// at 1 or more, such as "1.5 MiB"
+
+func byteSize(count int64) string {
+
+	if count < 1024 {
+		return fmt.Sprintf("%d B", count)
+	}
+
+	size := float64(count)
+	units := []string{"KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+	unit := -1
+	for size >= 1024 && unit < len(units)-1 {
+		size /= 1024
+		unit++
+	}
+
+	return fmt.Sprintf("%.1f %s", size, units[unit])
+}
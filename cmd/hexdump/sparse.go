@@ -0,0 +1,113 @@
+package main
+
+/*
+	The -sparse dump of a file with holes, where each hole is shown as
+	one marker line rather than as lines of zeros:
+
+		00000ff0 :  00 00 00 00 00 00 00 00 00 00 00 00 00 00 00 00  : ................
+		000ff000 :  <hole: 1044480 zero bytes>
+		00100000 :  68 65 6c 6c 6f 00 00 00 00 00 00 00 00 00 00 00  : hello...........
+
+	Edits:
+
+		2026-10-14		lc 		Created for -sparse
+		2026-10-14		lc 		Hole lines start with their offset
+
+	Copyright (c) 2020 NOVA Industries Limited
+
+	No warrenty implied or otherwise
+
+*/
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/liam-collins/go-hexdump"
+)
+
+// extent is a run of a file that holds data, from start up to end
+
+type extent struct {
+	start uint64
+	end   uint64
+}
+
+// dumpSparse dumps the data extents of a file of the given size that
+// 		fall within the -skip and -length, each as a dump of its own,
+// 		with a hole marker for the gap before, between and after them.
+// 		r must be able to seek, so that each extent is dumped from its
+// 		own offset.
+
+func dumpSparse(ctx context.Context, w io.Writer, r io.Reader, extents []extent, size uint64, opts hexdump.Options) error {
+
+	seeker, ok := r.(io.Seeker)
+	if !ok {
+		return hexdump.DumpContext(ctx, w, r, opts)
+	}
+
+	start := opts.Skip
+	if start > size {
+		return hexdump.ErrSkipPastEnd
+	}
+
+	end := size
+	if opts.Length > 0 && opts.Length < end-start {
+		end = start + opts.Length
+	}
+
+	position := start
+	for _, data := range extents {
+		from, to := max(data.start, start), min(data.end, end)
+		if from >= to {
+			continue
+		}
+
+		if from > position {
+			printHole(w, position, from-position, opts)
+		}
+
+		// Skip seeks on from where the stream is, so each extent
+		// starts from the top
+		if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+
+		opts.Skip, opts.Length = from, to-from
+		if err := hexdump.DumpContext(ctx, w, r, opts); err != nil {
+			return err
+		}
+
+		// Only the first extent has the -header ruler
+		opts.Header = false
+		position = to
+	}
+
+	if end > position {
+		printHole(w, position, end-position, opts)
+	}
+
+	return nil
+}
+
+// printHole prints the marker line for a hole of n zero bytes at
+// 		offset, laid out as a dump line with the marker in place of the
+// 		hex bytes, as the -trim lines are
+
+func printHole(w io.Writer, offset uint64, n uint64, opts hexdump.Options) {
+
+	marker := fmt.Sprintf("<hole: %d zero bytes>", n)
+	if opts.Separator == "" && !opts.NoOffset {
+		marker = " " + marker
+	}
+
+	switch {
+	case opts.NoOffset:
+		fmt.Fprintln(w, marker)
+	case opts.Separator != "":
+		fmt.Fprintf(w, "%s%s%s\n", hexdump.FormatOffset(offset, opts), opts.Separator, marker)
+	default:
+		fmt.Fprintf(w, "%s : %s\n", hexdump.FormatOffset(offset, opts), marker)
+	}
+}
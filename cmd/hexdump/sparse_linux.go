@@ -0,0 +1,74 @@
+package main
+
+/*
+	The data extents of a sparse file on Linux, found with lseek and
+	SEEK_DATA and SEEK_HOLE.
+
+	Edits:
+
+		2026-10-14		lc 		Created for -sparse
+
+	Copyright (c) 2020 NOVA Industries Limited
+
+	No warrenty implied or otherwise
+
+*/
+
+import (
+	"errors"
+	"io"
+	"os"
+	"syscall"
+)
+
+// The lseek whence values for the next data and the next hole, which
+// the syscall package does not have
+
+const (
+	seekData = 3
+	seekHole = 4
+)
+
+// dataExtents returns the extents of the named file that hold data, in
+// 		order, and the size of the file. ok is false if the file has no
+// 		holes, or they cannot be found, and it should be dumped as
+// 		usual. A file that is all hole has no extents.
+
+func dataExtents(name string) (extents []extent, size uint64, ok bool) {
+
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, 0, false
+	}
+	defer file.Close()
+
+	end, err := file.Seek(0, io.SeekEnd)
+	if err != nil || end == 0 {
+		return nil, 0, false
+	}
+
+	for offset := int64(0); offset < end; {
+		start, err := file.Seek(offset, seekData)
+		if errors.Is(err, syscall.ENXIO) {
+			// Only a hole is left
+			break
+		}
+		if err != nil {
+			return nil, 0, false
+		}
+
+		stop, err := file.Seek(start, seekHole)
+		if err != nil {
+			return nil, 0, false
+		}
+
+		extents = append(extents, extent{start: uint64(start), end: uint64(stop)})
+		offset = stop
+	}
+
+	if len(extents) == 1 && extents[0] == (extent{start: 0, end: uint64(end)}) {
+		return nil, 0, false
+	}
+
+	return extents, uint64(end), true
+}
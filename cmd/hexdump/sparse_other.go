@@ -0,0 +1,24 @@
+//go:build !linux
+
+package main
+
+/*
+	The data extents of a sparse file where SEEK_DATA and SEEK_HOLE are
+	not used, every file is dumped as usual.
+
+	Edits:
+
+		2026-10-14		lc 		Created for -sparse
+
+	Copyright (c) 2020 NOVA Industries Limited
+
+	No warrenty implied or otherwise
+
+*/
+
+// dataExtents always gives ok false, so that the file is dumped as usual
+
+func dataExtents(name string) (extents []extent, size uint64, ok bool) {
+
+	return nil, 0, false
+}
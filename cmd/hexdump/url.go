@@ -0,0 +1,81 @@
+package main
+
+/*
+	Fetching of http and https URLs given on the command line.
+
+	Edits:
+
+		2026-10-14		lc 		Created for dumping URLs
+
+	Copyright (c) 2020 NOVA Industries Limited
+
+	No warrenty implied or otherwise
+
+*/
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/liam-collins/go-hexdump"
+)
+
+// isURL reports whether a command line argument is an http or https URL
+// rather than a file name
+
+func isURL(name string) bool {
+
+	return strings.HasPrefix(name, "http://") || strings.HasPrefix(name, "https://")
+}
+
+// openURL fetches a URL and returns its response body as a stream.
+// 		A response that is not 2xx is an error, so the URL is skipped
+// 		in the same way as a file that cannot be opened. The offset
+// 		scale is taken from the Content-Length when the server gives
+// 		one. The timeout covers the whole request, including reading
+// 		the body.
+//
+//		If gunzip is set and the body starts with the gzip magic
+//		number the stream is the decompressed body.
+
+func openURL(url string, gunzip bool, timeout time.Duration) (io.ReadCloser, hexdump.Scale, error) {
+
+	client := &http.Client{Timeout: timeout}
+
+	response, err := client.Get(url)
+	if err != nil {
+		return nil, hexdump.Scale64, err
+	}
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		response.Body.Close()
+		return nil, hexdump.Scale64, fmt.Errorf("GET %s: %s", url, response.Status)
+	}
+
+	scale := hexdump.Scale64
+	if response.ContentLength >= 0 {
+		scale = sizeScale(response.ContentLength)
+	}
+
+	if !gunzip {
+		return response.Body, scale, nil
+	}
+
+	r, compressed, err := gunzipReader(response.Body)
+	switch {
+	case err != nil:
+		response.Body.Close()
+		return nil, hexdump.Scale64, fmt.Errorf("GET %s: %s", url, err)
+	case compressed:
+		return gzipFile{Reader: r.(*gzip.Reader), file: response.Body}, hexdump.Scale64, nil
+	default:
+		return struct {
+			io.Reader
+			io.Closer
+		}{r, response.Body}, scale, nil
+	}
+}
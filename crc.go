@@ -0,0 +1,51 @@
+package hexdump
+
+/*
+	The checksum shown for each line with LineCRC, the CRC-16/CCITT-FALSE
+	of the line's bytes: polynomial 0x1021, initial value 0xFFFF, no
+	reflection and no final XOR. The CRC of "123456789" is 29b1.
+
+	Edits:
+
+		2026-10-14		lc 		Created from scratch
+
+	Copyright (c) 2020 NOVA Industries Limited
+
+	No warrenty implied or otherwise
+
+*/
+
+const (
+	crc16Polynomial = 0x1021
+	crc16Initial    = 0xFFFF
+)
+
+// crc16 returns the CRC-16/CCITT-FALSE of data. A line is at most
+// MaxWidth bytes so it is worked out a bit at a time, with no table.
+func crc16(data []byte) uint16 {
+
+	crc := uint16(crc16Initial)
+	for _, ch := range data {
+		crc ^= uint16(ch) << 8
+		for range 8 {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ crc16Polynomial
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+
+	return crc
+}
+
+// appendCRC appends the CRC of the line to buffer as 4 hex digits
+func (d *dumper) appendCRC(buffer []byte, line Line) []byte {
+
+	crc := crc16(line.Bytes)
+	for shift := 12; shift >= 0; shift -= 4 {
+		buffer = append(buffer, d.hexDigits[crc>>shift&0x0F])
+	}
+
+	return buffer
+}
@@ -0,0 +1,61 @@
+package hexdump
+
+/*
+	CSV output for a dump, for loading into a spreadsheet. A header row
+	is followed by one row for each line of the dump:
+
+		offset,hex,ascii
+		0,68656c6c6f2c20776f726c64,"hello, world"
+
+	The ascii field is always quoted, whatever its text, so every row
+	has the same layout for a tool that splits it.
+
+	Edits:
+
+		2026-10-14		lc 		Created from scratch
+		2026-10-14		lc 		The ascii field always quoted
+
+	Copyright (c) 2020 NOVA Industries Limited
+
+	No warrenty implied or otherwise
+
+*/
+
+import "strconv"
+
+// csvHeader is the first row of the CSV output
+const csvHeader = "offset,hex,ascii\n"
+
+// printCSV writes one line of the dump as a CSV row. The offset is in
+// decimal whatever the Radix, so that a spreadsheet sorts it as a
+// number, and the hex digits are run together. Neither needs quoting,
+// while the ascii field is always quoted, with any quote in it doubled
+// as RFC 4180 has it.
+func (d *dumper) printCSV(line Line) {
+
+	buffer := strconv.AppendUint(d.lineBuffer[:0], line.Offset, 10)
+	buffer = append(buffer, ',')
+	for _, ch := range line.Bytes {
+		buffer = append(buffer, d.hexDigits[ch>>4], d.hexDigits[ch&0x0F])
+	}
+	buffer = append(buffer, ',', '"')
+
+	ascii := len(buffer)
+	buffer = d.appendASCII(buffer, line)
+	for i := ascii; i < len(buffer); i++ {
+		if buffer[i] == '"' {
+			buffer = append(buffer[:i+1], buffer[i:]...)
+			i++
+		}
+	}
+	buffer = append(buffer, '"', '\n')
+
+	d.w.Write(buffer)
+	d.lineBuffer = buffer
+}
+
+// printCSVHeader writes the header row of the CSV output
+func (d *dumper) printCSVHeader() {
+
+	d.w.Write([]byte(csvHeader))
+}
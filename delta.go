@@ -0,0 +1,78 @@
+package hexdump
+
+/*
+	The Delta column, the signed difference between each byte and the
+	byte before it in the stream, for spotting counters and gradients:
+
+		0010 :  20 21 23 27 2f 3f 00 ff  :  !#'/?..  :   +1   +1   +2   +4   +8  +16  -63   -1
+
+	Edits:
+
+		2026-10-14		lc 		Created from scratch
+
+	Copyright (c) 2020 NOVA Industries Limited
+
+	No warrenty implied or otherwise
+
+*/
+
+import "strconv"
+
+const deltaWidth = 4 // places taken by each delta, "-128" the widest
+
+// noteDelta keeps the byte before the line, for its delta column, and
+// the last byte of the line for the line after it
+func (d *dumper) noteDelta(line Line) {
+
+	d.deltaByte, d.deltaKnown = d.lastByte, d.lastKnown
+	if n := len(line.Bytes); n > 0 {
+		d.lastByte, d.lastKnown = line.Bytes[n-1], true
+	}
+}
+
+// appendDelta appends the delta column of the line to buffer. Each
+// delta is taken modulo 256 as a signed byte, so a counter that wraps
+// from ff to 00 shows +1. The first byte of the stream has no byte
+// before it and is left blank. A short line is padded out to the full
+// width if pad is set.
+func (d *dumper) appendDelta(buffer []byte, line Line, pad bool) []byte {
+
+	previous, known := d.deltaByte, d.deltaKnown
+
+	for i, ch := range line.Bytes {
+		if i > 0 {
+			buffer = append(buffer, ' ')
+		}
+
+		if !known {
+			buffer = append(buffer, "    "...)
+			previous, known = ch, true
+			continue
+		}
+
+		delta := int64(int8(ch - previous))
+		text := strconv.FormatInt(delta, 10)
+		if delta > 0 {
+			text = "+" + text
+		}
+		for n := len(text); n < deltaWidth; n++ {
+			buffer = append(buffer, ' ')
+		}
+		buffer = append(buffer, text...)
+		previous = ch
+	}
+
+	if pad && !d.separated {
+		for n := len(line.Bytes); n < d.displayWidth; n++ {
+			buffer = append(buffer, "     "...)
+		}
+	}
+
+	return buffer
+}
+
+// deltaColumnWidth is the width of a full line of the delta column
+func (d *dumper) deltaColumnWidth() int {
+
+	return d.displayWidth*(deltaWidth+1) - 1
+}
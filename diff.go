@@ -0,0 +1,383 @@
+package hexdump
+
+/*
+	Diff dumps two streams side by side, showing only the lines where
+	they differ. Each differing line is shown twice, first from the
+	stream a (marked "<") then from the stream b (marked ">"):
+
+		0010 <  73 20 69 73  : s is
+		0010 >  73 21 69 73  : s!is
+		           ^^             ^
+
+	Edits:
+
+		2026-10-14		lc 		Created from scratch
+		2026-10-14		lc 		Find option ignored
+		2026-10-14		lc 		Compare for the first difference only
+		2026-10-14		lc 		Width limited to MaxWidth
+		2026-10-14		lc 		Relative option applied
+		2026-10-14		lc 		OffsetDigits option applied
+		2026-10-14		lc 		DiffReference with the lines side by side
+		2026-10-14		lc 		Columns and carets built in a strings.Builder
+
+	Copyright (c) 2020 NOVA Industries Limited
+
+	No warrenty implied or otherwise
+
+*/
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	colorDiff = "\x1b[31m" // red
+
+	diffMarkerA = " < "
+	diffMarkerB = " > "
+
+	referenceSeparator = "  | " // between a line and its reference
+)
+
+// differ holds the layout used to print the lines of a diff
+type differ struct {
+	w            io.Writer
+	offsetFormat string
+	byteFormat   string
+	hexWidth     int
+	displayWidth int
+	group        int
+	color        bool
+	chars        charMapper
+	sideBySide   bool // a line and its reference are on one line
+}
+
+// Diff reads the streams a and b in step and writes the lines where
+// they differ to w. The bytes that differ are marked with a caret line
+// under the pair of lines or, when Color is set, shown in red. If one
+// stream is longer than the other its extra tail is shown on its own.
+// The Width, Scale, OffsetDigits, Radix, Upper, Group, Color, Dot,
+// Latin1, Skip, Relative and Length options are used. ErrWidth is
+// returned if the Width is over MaxWidth.
+func Diff(w io.Writer, a io.Reader, b io.Reader, opts Options) error {
+
+	return diff(w, a, b, opts, false)
+}
+
+// DiffReference is Diff for checking the stream r against a reference
+// stream ref. Each line where they differ is printed once, with the
+// bytes of ref alongside those of r and the offset of the line in r:
+//
+//	0010 :  73 21 69 73  : s!is  |  73 20 69 73  : s is
+//	                ^^        ^              ^^        ^
+//
+// The lines where they match are left out. A line past the end of
+// either stream has that side left blank. The options are used as by
+// Diff.
+func DiffReference(w io.Writer, r io.Reader, ref io.Reader, opts Options) error {
+
+	return diff(w, r, ref, opts, true)
+}
+
+// diff is Diff, with the pairs of lines side by side if sideBySide is
+// set
+func diff(w io.Writer, a io.Reader, b io.Reader, opts Options, sideBySide bool) error {
+
+	// Teeing both streams into the one writer would interleave them,
+	// and the two streams could match Find at different offsets
+	opts.Tee = nil
+	opts.Find = nil
+
+	if opts.Width > MaxWidth {
+		return ErrWidth
+	}
+
+	displayWidth := opts.Width
+	if displayWidth <= 0 {
+		displayWidth = NormalWidth
+	}
+
+	group := opts.Group
+	if group < 0 {
+		group = 0
+	}
+
+	d := &differ{
+		w:            w,
+		offsetFormat: caseFormat(columnFormat(opts, opts.Radix), opts.Upper),
+		byteFormat:   caseFormat(hex8Bits, opts.Upper),
+		hexWidth:     hexColumnWidth(displayWidth, group, 1, 2),
+		displayWidth: displayWidth,
+		group:        group,
+		color:        opts.Color,
+		chars:        newCharMapper(opts),
+		sideBySide:   sideBySide,
+	}
+
+	a, offset, err := limitInput(a, opts)
+	if err != nil {
+		return err
+	}
+	b, _, err = limitInput(b, opts)
+	if err != nil {
+		return err
+	}
+
+	lineA := make([]byte, displayWidth)
+	lineB := make([]byte, displayWidth)
+
+	for {
+		// Keep the lines aligned to the width, as Dump does
+		size := displayWidth - int(offset%uint64(displayWidth))
+
+		n, errA := readLine(a, lineA[:size])
+		m, errB := readLine(b, lineB[:size])
+
+		if errA != nil {
+			return errA
+		}
+		if errB != nil {
+			return errB
+		}
+		if n == 0 && m == 0 {
+			return nil
+		}
+
+		if !bytes.Equal(lineA[:n], lineB[:m]) {
+			d.printPair(offset, lineA[:n], lineB[:m])
+		}
+
+		if n > m {
+			offset += uint64(n)
+		} else {
+			offset += uint64(m)
+		}
+	}
+}
+
+// Compare reads the streams a and b in step until they differ and
+// returns the offset of the first byte that is not the same in both,
+// with same false. If one stream is a prefix of the other they differ
+// at the end of the shorter one. same is true if the streams are
+// identical. The Skip and Length options are used, the offset counts
+// from the start of the streams, or from Skip with Relative.
+func Compare(a io.Reader, b io.Reader, opts Options) (offset uint64, same bool, err error) {
+
+	opts.Tee = nil
+	opts.Find = nil
+
+	a, offset, err = limitInput(a, opts)
+	if err != nil {
+		return
+	}
+	b, _, err = limitInput(b, opts)
+	if err != nil {
+		return
+	}
+
+	blockA := make([]byte, bufferSize)
+	blockB := make([]byte, bufferSize)
+
+	for {
+		n, errA := readLine(a, blockA)
+		m, errB := readLine(b, blockB)
+
+		if errA != nil {
+			return offset, false, errA
+		}
+		if errB != nil {
+			return offset, false, errB
+		}
+
+		for i := range min(n, m) {
+			if blockA[i] != blockB[i] {
+				return offset + uint64(i), false, nil
+			}
+		}
+
+		if n != m {
+			return offset + uint64(min(n, m)), false, nil
+		}
+		if n == 0 {
+			return offset, true, nil
+		}
+
+		offset += uint64(n)
+	}
+}
+
+// readLine fills line from r, returning how many bytes were read. The
+// end of the stream is not an error, it just gives a short line.
+func readLine(r io.Reader, line []byte) (int, error) {
+
+	n, err := io.ReadFull(r, line)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		err = nil
+	}
+
+	return n, err
+}
+
+// printPair prints a pair of differing lines. A line that is past the
+// end of its stream is left out.
+func (d *differ) printPair(offset uint64, lineA []byte, lineB []byte) {
+
+	if d.sideBySide {
+		d.printRow(offset, lineA, lineB)
+		return
+	}
+
+	if len(lineA) > 0 {
+		d.printSide(offset, diffMarkerA, lineA, lineB)
+	}
+	if len(lineB) > 0 {
+		d.printSide(offset, diffMarkerB, lineB, lineA)
+	}
+
+	if !d.color {
+		d.printCarets(offset, lineA, lineB)
+	}
+}
+
+// printSide prints one side of a differing line, colouring the bytes
+// that are not the same as in the other line
+func (d *differ) printSide(offset uint64, marker string, line []byte, other []byte) {
+
+	hexDigits, chrDigits := d.columns(line, other)
+
+	fmt.Fprintf(d.w, d.offsetFormat+marker+"%s"+asciiSeparator+"%s\n", offset, hexDigits, chrDigits)
+}
+
+// printRow prints a differing line with its reference alongside, and
+// the carets under both unless colouring
+func (d *differ) printRow(offset uint64, line []byte, ref []byte) {
+
+	hexDigits, chrDigits := d.columns(line, ref)
+	refHex, refChr := d.columns(ref, line)
+
+	// The character column takes one place per byte, whatever the
+	// bytes of its text
+	chrDigits += strings.Repeat(" ", d.displayWidth-len(line))
+
+	fmt.Fprintf(d.w, d.offsetFormat+offsetSeparator+"%s"+asciiSeparator+"%s"+referenceSeparator+"%s"+asciiSeparator+"%s\n",
+		offset, hexDigits, chrDigits, refHex, refChr)
+
+	if d.color {
+		return
+	}
+
+	hexCarets, chrCarets := d.carets(line, ref)
+	indent := len(fmt.Sprintf(d.offsetFormat, offset)) + len(offsetSeparator)
+
+	var carets strings.Builder
+	pad(&carets, "", indent)
+	pad(&carets, hexCarets, d.hexWidth+len(asciiSeparator))
+	pad(&carets, chrCarets, d.displayWidth+len(referenceSeparator))
+	pad(&carets, hexCarets, d.hexWidth+len(asciiSeparator))
+	carets.WriteString(chrCarets)
+
+	fmt.Fprintln(d.w, strings.TrimRight(carets.String(), " "))
+}
+
+// pad writes text to b followed by the spaces that make it up to width
+func pad(b *strings.Builder, text string, width int) {
+
+	b.WriteString(text)
+	for n := len(text); n < width; n++ {
+		b.WriteByte(' ')
+	}
+}
+
+// columns returns the hex column, padded to its full width, and the
+// character column for one side of a differing line, colouring the
+// bytes that are not the same as in the other line
+func (d *differ) columns(line []byte, other []byte) (string, string) {
+
+	var hexDigits strings.Builder
+	var chrDigits strings.Builder
+	visible := 0
+
+	for i, ch := range line {
+
+		if d.group > 0 && i > 0 && i%d.group == 0 {
+			hexDigits.WriteByte(' ')
+			visible++
+		}
+
+		colored := d.color && differs(i, line, other)
+
+		hexDigits.WriteByte(' ')
+		if colored {
+			hexDigits.WriteString(colorDiff)
+			chrDigits.WriteString(colorDiff)
+		}
+		fmt.Fprintf(&hexDigits, d.byteFormat, ch)
+		chrDigits.WriteString(d.chars.char(ch))
+		if colored {
+			hexDigits.WriteString(colorReset)
+			chrDigits.WriteString(colorReset)
+		}
+		visible += 3
+	}
+
+	for ; visible < d.hexWidth; visible++ {
+		hexDigits.WriteByte(' ')
+	}
+
+	return hexDigits.String(), chrDigits.String()
+}
+
+// printCarets prints a line with a "^" under each byte that differs,
+// in both the hex and the ASCII columns
+func (d *differ) printCarets(offset uint64, lineA []byte, lineB []byte) {
+
+	indent := len(fmt.Sprintf(d.offsetFormat, offset)) + len(diffMarkerA)
+	hexCarets, chrCarets := d.carets(lineA, lineB)
+
+	var carets strings.Builder
+	pad(&carets, "", indent)
+	pad(&carets, hexCarets, d.hexWidth+len(asciiSeparator))
+	carets.WriteString(chrCarets)
+
+	fmt.Fprintln(d.w, strings.TrimRight(carets.String(), " "))
+}
+
+// carets returns the hex and character columns of a caret line, with a
+// "^" under each byte that differs between the two lines
+func (d *differ) carets(lineA []byte, lineB []byte) (string, string) {
+
+	longest := lineA
+	if len(lineB) > len(lineA) {
+		longest = lineB
+	}
+
+	var hexCarets strings.Builder
+	var chrCarets strings.Builder
+
+	for i := range longest {
+
+		if d.group > 0 && i > 0 && i%d.group == 0 {
+			hexCarets.WriteByte(' ')
+		}
+
+		if differs(i, lineA, lineB) {
+			hexCarets.WriteString(" ^^")
+			chrCarets.WriteByte('^')
+		} else {
+			hexCarets.WriteString("   ")
+			chrCarets.WriteByte(' ')
+		}
+	}
+
+	return hexCarets.String(), chrCarets.String()
+}
+
+// differs reports whether byte i of line is different in other. A byte
+// missing from either line counts as a difference.
+func differs(i int, line []byte, other []byte) bool {
+
+	return i >= len(line) || i >= len(other) || line[i] != other[i]
+}
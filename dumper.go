@@ -0,0 +1,112 @@
+package hexdump
+
+/*
+	Dumper keeps the layout worked out from one set of Options, for
+	dumping many inputs without working it out again for each of them.
+
+	Edits:
+
+		2026-10-14		lc 		Created from scratch
+		2026-10-14		lc 		Layout compiled once, Bytes returns its error
+		2026-10-14		lc 		Dump counts the bytes written, as WriteTo does
+
+	Copyright (c) 2020 NOVA Industries Limited
+
+	No warrenty implied or otherwise
+
+*/
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+)
+
+// Dumper dumps each input it is given with the Options it was made
+// with. NewDumper checks the Options and compiles their layout, the
+// offset format, column widths, separators and character mapping, once,
+// and every dump then starts from a copy of it.
+//
+// The zero Dumper is ready to use, with the zero Options: the default
+// 16 byte wide layout that Dump gives. Its layout is compiled on its
+// first dump.
+//
+// Each dump keeps its own state, so a Dumper can be used by several
+// goroutines at once, as long as its Options have no Totals or Tee.
+// Those are written by every dump without a lock.
+type Dumper struct {
+	opts   Options
+	once   sync.Once // compiles the layout on the first use
+	layout *dumper   // copied for each dump
+	err    error     // why the Options cannot be used
+}
+
+// NewDumper returns a Dumper with the layout of opts. ErrWidth is
+// returned if the Width is over MaxWidth.
+func NewDumper(opts Options) (*Dumper, error) {
+
+	d := &Dumper{opts: opts}
+	if err := d.compile(); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// compile works out the layout, the first time it is called, and
+// returns the error of doing so
+func (d *Dumper) compile() error {
+
+	d.once.Do(func() {
+		d.layout, d.opts, d.err = compileLayout(d.opts)
+	})
+
+	return d.err
+}
+
+// Dump writes the dump of r to w and returns the number of bytes
+// written, as io.WriterTo does. The dump is the one Dump gives with the
+// Dumper's Options.
+func (d *Dumper) Dump(w io.Writer, r io.Reader) (int64, error) {
+
+	return d.DumpContext(context.Background(), w, r)
+}
+
+// DumpContext is Dump with a context to cancel it, which is checked as
+// DumpContext checks it
+func (d *Dumper) DumpContext(ctx context.Context, w io.Writer, r io.Reader) (int64, error) {
+
+	if err := d.compile(); err != nil {
+		return 0, err
+	}
+
+	counter := &countingWriter{w: w}
+	err := d.layout.run(ctx, counter, r, d.opts)
+
+	return counter.n, err
+}
+
+// Bytes returns the dump of data. As with DumpString empty data gives
+// an empty dump, while a Skip past its end gives ErrSkipPastEnd.
+func (d *Dumper) Bytes(data []byte) ([]byte, error) {
+
+	var buffer bytes.Buffer
+	_, err := d.Dump(&buffer, bytes.NewReader(data))
+
+	return buffer.Bytes(), err
+}
+
+// countingWriter counts the bytes written through it to w
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+
+	return n, err
+}
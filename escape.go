@@ -0,0 +1,155 @@
+package hexdump
+
+/*
+	GoString and CString write a stream as a quoted string literal, so
+	that a short binary can be pasted into source code. The literal is
+	split over several lines, joined with "+" in Go and by putting the
+	literals next to each other in C:
+
+		"\x7fELF\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00" +
+		"\x03\x00>\x00\x01\x00\x00\x00"
+
+	Edits:
+
+		2026-10-14		lc 		Created from scratch
+
+	Copyright (c) 2020 NOVA Industries Limited
+
+	No warrenty implied or otherwise
+
+*/
+
+import (
+	"bufio"
+	"io"
+)
+
+const stringBytesPerLine = 16
+
+// stringStyle is how a string literal is written in one language
+type stringStyle struct {
+	indent   string
+	join     string                    // after every line but the last
+	escape   func([]byte, byte) []byte // appends the escape of a byte
+	trigraph bool                      // "??" has to be broken up
+}
+
+// namedEscapes are the escapes that Go and C share for the control
+// characters, and for the quote and backslash
+var namedEscapes = map[byte]string{
+	'\a': `\a`, '\b': `\b`, '\f': `\f`, '\n': `\n`,
+	'\r': `\r`, '\t': `\t`, '\v': `\v`, '"': `\"`, '\\': `\\`,
+}
+
+// GoString writes the content of the stream r to w as a Go string
+// literal, a line for every 16 bytes (or after a newline) joined with
+// "+". Printable ASCII is written as it is, the usual control
+// characters as \n and the like, and every other byte as a \xNN escape.
+// Empty input gives "". The Skip, Find, Length, Tee, BufferSize and
+// Upper options are used, the rest only apply to Dump.
+func GoString(w io.Writer, r io.Reader, opts Options) error {
+
+	hexDigits := lowerHexDigits
+	if opts.Upper {
+		hexDigits = upperHexDigits
+	}
+
+	style := stringStyle{
+		indent: "\t",
+		join:   " +",
+		escape: func(buffer []byte, ch byte) []byte {
+			return append(buffer, '\\', 'x', hexDigits[ch>>4], hexDigits[ch&0x0F])
+		},
+	}
+
+	return quoteStream(w, r, style, opts)
+}
+
+// CString writes the content of the stream r to w as a C string literal
+// split over lines as GoString does, the lines being joined by the
+// compiler. The bytes that are not printable are written as 3 digit
+// octal escapes such as \177, as a \x escape in C takes in any hex
+// digits that follow it, and a "?" after a "?" is escaped so that it
+// cannot start a trigraph. The Skip, Find, Length, Tee and BufferSize
+// options are used, the rest only apply to Dump.
+func CString(w io.Writer, r io.Reader, opts Options) error {
+
+	style := stringStyle{
+		indent: "  ",
+		escape: func(buffer []byte, ch byte) []byte {
+			return append(buffer, '\\', '0'+(ch>>6), '0'+(ch>>3&7), '0'+(ch&7))
+		},
+		trigraph: true,
+	}
+
+	return quoteStream(w, r, style, opts)
+}
+
+// quoteStream writes the stream r to w as a string literal in the given
+// style
+func quoteStream(w io.Writer, r io.Reader, style stringStyle, opts Options) error {
+
+	r, _, err := limitInput(r, opts)
+	if err != nil {
+		return err
+	}
+
+	out := bufio.NewWriter(w)
+	buffer := make([]byte, readBufferSize(opts))
+
+	var line []byte
+	count := 0       // bytes on the current line
+	started := false // a line has been written
+
+	// Each line is only written once the next byte is seen, so that the
+	// last line can be left without the join
+	endLine := func() {
+		if started {
+			out.WriteString(style.join + "\n")
+		}
+		out.WriteString(style.indent + `"`)
+		out.Write(line)
+		out.WriteString(`"`)
+		line = line[:0]
+		count = 0
+		started = true
+	}
+
+	var last byte
+	for {
+		bufferRead, err := r.Read(buffer)
+
+		for _, ch := range buffer[:bufferRead] {
+			if count == stringBytesPerLine || count > 0 && last == '\n' {
+				endLine()
+			}
+
+			switch name, named := namedEscapes[ch]; {
+			case named:
+				line = append(line, name...)
+			case style.trigraph && ch == '?' && count > 0 && last == '?':
+				line = append(line, '\\', '?')
+			case isPrintable(ch):
+				line = append(line, ch)
+			default:
+				line = style.escape(line, ch)
+			}
+
+			last = ch
+			count++
+		}
+
+		if err != nil {
+			if err != io.EOF {
+				out.Flush()
+				return err
+			}
+			break
+		}
+	}
+
+	endLine()
+	out.WriteString("\n")
+
+	return out.Flush()
+}
@@ -0,0 +1,60 @@
+package hexdump
+
+/*
+	Filtering of a dump down to the lines that hold a byte pattern,
+	with context lines around them, in the manner of grep -C.
+
+	Edits:
+
+		2026-10-14		lc 		Created from scratch
+		2026-10-14		lc 		No "--" lines in CSV output
+
+	Copyright (c) 2020 NOVA Industries Limited
+
+	No warrenty implied or otherwise
+
+*/
+
+import "bytes"
+
+const grepSeparator = "--\n"
+
+// grepLine is used as writeLine with Grep. A line holding the pattern
+// is printed along with the context lines kept before it, and the next
+// context lines after it are printed too. Any other line is kept, up to
+// context of them, in case a match follows.
+func (d *dumper) grepLine(line Line) {
+
+	switch {
+	case bytes.Contains(line.Bytes, d.grep):
+		for _, kept := range d.before {
+			d.emit(kept)
+		}
+		d.before = d.before[:0]
+		d.emit(line)
+		d.after = d.context
+
+	case d.after > 0:
+		d.emit(line)
+		d.after--
+
+	case d.context > 0:
+		if len(d.before) == d.context {
+			d.before = append(d.before[:0], d.before[1:]...)
+		}
+		d.before = append(d.before, Line{Offset: line.Offset, Bytes: bytes.Clone(line.Bytes)})
+	}
+}
+
+// grepPrint prints a line kept by grep, after a "--" line if there is
+// a gap between it and the last line printed
+func (d *dumper) grepPrint(line Line) {
+
+	if d.printed && line.Offset != d.nextOffset && d.encoder == nil && !d.table {
+		d.w.Write([]byte(grepSeparator))
+	}
+
+	d.output(line)
+	d.printed = true
+	d.nextOffset = line.Offset + uint64(len(line.Bytes))
+}
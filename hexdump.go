@@ -1,16 +1,87 @@
-package main
+// Package hexdump formats an io stream as a hex and ASCII dump. It
+// holds the dumping logic used by the hexdump command so that it can be
+// called from other Go programs. Each line of the default output is
+//
+//	<File Offset>   <hex> ... <hex>  : <printable ASCII chars>
+//
+// Dump writes the dump of a stream, and a Dumper dumps many streams
+// with one set of Options. Reverse reads a dump back into its bytes.
+package hexdump
 
 /*
-	hexdump outputs a hex and ASCII dump, of a io stream, to STDOUT.
+	hexdump formats an io stream as a hex and ASCII dump.
 
-	The program will either read in from STDIN or take 1 or more
-	REGULAR files and process them as an IO stream. If a given file
-	is not a REGULAR file or the user does not have persmission to
-	the file then it is skipped.
+	The package holds the dumping logic used by the hexdump command
+	so that it can be called from other Go programs. The output format
+	is:
+
+		<File Offset>   <hex> ... <hex>  : <printable ASCII chars>
 
 	Edits:
 
 		2020-08-26		lc 		Created from scratch
+		2026-10-14		lc 		hexdump writes to an io.Writer rather than STDOUT
+		2026-10-14		lc 		Moved out of package main into a library
+		2026-10-14		lc 		Optional squeezing of duplicate lines
+		2026-10-14		lc 		Skip to a byte offset before dumping
+		2026-10-14		lc 		Limit the number of bytes dumped
+		2026-10-14		lc 		Column separators shared with Reverse
+		2026-10-14		lc 		Decimal and octal offset addressing
+		2026-10-14		lc 		Lower case hex by default, upper case option
+		2026-10-14		lc 		Optional grouping of the hex bytes
+		2026-10-14		lc 		Optional colouring of the hex bytes
+		2026-10-14		lc 		Lines passed around as a Line struct
+		2026-10-14		lc 		Skip checks the size of seekable streams
+		2026-10-14		lc 		Bytes returned with an error are dumped
+		2026-10-14		lc 		Tee the dumped bytes to a writer
+		2026-10-14		lc 		Configurable placeholder for non printable bytes
+		2026-10-14		lc 		Optional Latin-1 character column
+		2026-10-14		lc 		Optional control character annotations
+		2026-10-14		lc 		Buffered output
+		2026-10-14		lc 		Lines built without string concatenation
+		2026-10-14		lc 		Partial lines carried over between reads
+		2026-10-14		lc 		DumpString for dumping a byte slice
+		2026-10-14		lc 		Dumper set up and read loop shared with Lines
+		2026-10-14		lc 		DumpContext to cancel a dump
+		2026-10-14		lc 		EBCDIC character column
+		2026-10-14		lc 		Hex column grouped into words of either endian
+		2026-10-14		lc 		Optional ruler line above the dump
+		2026-10-14		lc 		Optional binary byte column
+		2026-10-14		lc 		Start the dump at the first match of a pattern
+		2026-10-14		lc 		Highlight the matches of a pattern
+		2026-10-14		lc 		Only print the lines holding a pattern
+		2026-10-14		lc 		Collapse runs of padding lines
+		2026-10-14		lc 		No header for empty input
+		2026-10-14		lc 		Option to leave out the ASCII column
+		2026-10-14		lc 		Option to choose the column separator
+		2026-10-14		lc 		CSV output
+		2026-10-14		lc 		Lines laid out by a text/template
+		2026-10-14		lc 		Optional guess at the file type
+		2026-10-14		lc 		Optional 7-bit character column
+		2026-10-14		lc 		Optional line numbers in place of offsets
+		2026-10-14		lc 		Width limited to MaxWidth
+		2026-10-14		lc 		Offsets relative to the start of the dump
+		2026-10-14		lc 		Sampling of every Step'th line
+		2026-10-14		lc 		Optional offset at the end of each line
+		2026-10-14		lc 		Totals of the bytes and lines dumped
+		2026-10-14		lc 		Canonical hexdump -C layout
+		2026-10-14		lc 		od style types for the value column
+		2026-10-14		lc 		Offset column of a fixed number of digits
+		2026-10-14		lc 		Fixed size records split by a rule
+		2026-10-14		lc 		Optional size of the read buffer
+		2026-10-14		lc 		Optional UTF-8 character column
+		2026-10-14		lc 		Optional character column before the hex
+		2026-10-14		lc 		Optional CRC of each line
+		2026-10-14		lc 		Optional count of the bytes squeezed
+		2026-10-14		lc 		Optional delta between consecutive bytes
+		2026-10-14		lc 		Optional Unix time in each record
+		2026-10-14		lc 		Option to leave out the offset column
+		2026-10-14		lc 		Layout compiled apart from the dump
+		2026-10-14		lc 		DumpBytes to dump a slice without copying it
+		2026-10-14		lc 		FormatOffset for lines printed alongside a dump
+		2026-10-14		lc 		Each Options field documented on the field
+		2026-10-14		lc 		Package comment, doc comments attached to their declarations
+		2026-10-14		lc 		JSON lines written through the dump's buffer
 
 	Copyright (c) 2020 NOVA Industries Limited
 
@@ -19,184 +90,1575 @@ package main
 */
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
-	"flag"
 	"fmt"
 	"io"
-	"os"
+	"strings"
+	"text/template"
 )
 
 const (
 	bufferSize = 4096
 
-	normalWidth    = 16
-	wideWidth      = 32
-	extraWideWidth = 64
+	// NormalWidth, WideWidth and ExtraWideWidth are the standard
+	// number of bytes displayed per line.
+	NormalWidth    = 16
+	WideWidth      = 32
+	ExtraWideWidth = 64
 
-	maxUint16 = ^uint16(0)
-	maxUint32 = ^uint32(0)
+	// MaxWidth is the widest line allowed, so that a mistyped width
+	// cannot have a line take up a huge amount of memory
+	MaxWidth = 256
 
 	hex8Bits  = "%2.2X"
 	hex16Bits = "%4.4X"
 	hex32Bits = "%8.8X"
 	hex64Bits = "%16.16X"
 
+	dec16Bits = "%5.5d"
+	dec32Bits = "%10.10d"
+	dec64Bits = "%20.20d"
+
+	oct16Bits = "%6.6o"
+	oct32Bits = "%11.11o"
+	oct64Bits = "%22.22o"
+
+	lowerHexDigits = "0123456789abcdef"
+	upperHexDigits = "0123456789ABCDEF"
+
 	chSpace = 0x20
 	chDel   = 0x7F
+
+	colorNull      = "\x1b[90m" // dim gray
+	colorPrintable = "\x1b[32m" // green
+	colorHigh      = "\x1b[33m" // yellow
+	colorHighlight = "\x1b[7m"  // reverse video
+	colorReset     = "\x1b[0m"
+
+	offsetSeparator = " : "
+	asciiSeparator  = "  : "
+	squeezeMarker   = "*"
+	squeezeCounted  = "* (0x%x bytes identical)"
+	paddingFormat   = "[%d bytes of %s]"
+)
+
+// ErrSkipPastEnd is returned by Dump when the stream ends before the
+// Skip offset is reached
+var ErrSkipPastEnd = errors.New("hexdump: skip offset is past the end of the input")
+
+// ErrWidth is returned, before anything is read or written, when the
+// Width is more than MaxWidth
+var ErrWidth = fmt.Errorf("hexdump: width is more than %d bytes", MaxWidth)
+
+// ErrNotFound is returned when the Find pattern is not in the stream
+var ErrNotFound = errors.New("hexdump: pattern not found in the input")
+
+// Scale is the size of the address shown in the offset column.
+type Scale int
+
+const (
+	Scale64 Scale = iota // 64bit address, used for streams of unknown size
+	Scale16              // 16bit address
+	Scale32              // 32bit address
 )
 
-func main() {
+// Radix is the number base used for the offset column.
+type Radix int
+
+const (
+	Hex     Radix = iota // hex addresses, the default
+	Decimal              // decimal addresses
+	Octal                // octal addresses
+)
+
+// Format selects how each line of a dump is written.
+type Format int
+
+const (
+	Text      Format = iota // offset, hex and ASCII columns
+	JSON                    // one JSON object per line
+	CSV                     // a header row, then one row per line
+	Canonical               // the layout of hexdump -C
+)
+
+// Line is a single line of a dump: the offset, in the stream, of its
+// first byte and the bytes shown on the line.
+type Line struct {
+	Offset uint64
+	Bytes  []byte
+}
+
+// Totals counts the bytes and the lines of a dump. The lines are all
+// the lines of the dump, including any that are squeezed, sampled or
+// trimmed, and a short first or last line.
+type Totals struct {
+	Bytes uint64
+	Lines uint64
+}
+
+// Options controls the layout of a dump. The zero value gives the
+// default 16 byte wide text dump with a 64bit offset.
+type Options struct {
+	// Format selects the text layout (the default), JSON, CSV or
+	// Canonical. The JSON and CSV outputs do not squeeze or trim lines.
+	// Canonical is exactly the layout of hexdump -C, for tools that
+	// parse it: the layout options are all ignored, and only Squeeze,
+	// Step and the options picking the bytes to dump are used. The
+	// offset of the end of the dump is printed on a line of its own
+	// after the last line, and not after an empty dump.
+	Format Format
+
+	// Width is the number of bytes displayed per line, any value from 1
+	// up to MaxWidth. NormalWidth, WideWidth and ExtraWideWidth are the
+	// usual ones.
+	Width int
+
+	// Scale is the size of the offset column.
+	Scale Scale
+
+	// OffsetDigits, when more than 0, sizes the offset column to that
+	// many digits instead of by Scale, so that dumps of inputs of
+	// different sizes line up. An offset that needs more digits is
+	// still printed in full.
+	OffsetDigits int
+
+	// Radix selects hex (the default), decimal or octal offsets.
+	Radix Radix
+
+	// LineNumbers shows the number of each line, from 1, in the offset
+	// column of a text dump instead of its offset. The numbers are
+	// decimal whatever the Radix, zero padded to the width of a decimal
+	// offset of the Scale. The JSON and CSV offsets are unchanged and
+	// Reverse cannot read them.
+	LineNumbers bool
+
+	// Upper writes hex in upper case, both in the offset and the byte
+	// columns, rather than lower case.
+	Upper bool
+
+	// Group, when not zero, puts an extra space in the hex column after
+	// every Group bytes.
+	Group int
+
+	// Word, when more than 1, shows the hex column as words of that many
+	// bytes (2, 4 or 8, as od -t x2 does) with no spaces between the
+	// bytes of a word. Width should be a multiple of Word, and Group is
+	// rounded up to one. A short word at the end of the stream is shown
+	// in stream order. Reverse cannot read words back.
+	Word int
+
+	// LittleEndian shows the bytes of a word last byte first, rather
+	// than in stream order (big endian).
+	LittleEndian bool
+
+	// Binary shows each byte as 8 binary digits, as od -t b does, rather
+	// than 2 hex digits. Word and LittleEndian still apply, and Reverse
+	// cannot read it.
+	Binary bool
+
+	// Type shows each byte in another od -t type: signed or unsigned
+	// decimal, octal or as a character (a C escape for the control
+	// characters od names, octal for the other bytes that are not
+	// printable). Word and Binary only apply to HexByte. CharByte leaves
+	// out the character column, as NoASCII does, since the value column
+	// already shows the characters.
+	Type ByteType
+
+	// Color colours the hex column with ANSI escapes: nulls are dim
+	// gray, printable ASCII green and bytes 0x80 to 0xFF yellow.
+	Color bool
+
+	// Dot is the character shown in the ASCII column for bytes that are
+	// not printable, "." if it is not set.
+	Dot rune
+
+	// Latin1 also treats bytes 0xA0 to 0xFF as printable, showing them
+	// as their Latin-1 characters.
+	Latin1 bool
+
+	// EBCDIC decodes the character column as EBCDIC (code page 037)
+	// rather than ASCII. Latin1 is ignored with it and the hex column is
+	// unchanged.
+	EBCDIC bool
+
+	// Mnemonic adds a column after the ASCII column naming the control
+	// characters on the line, such as "0a=LF" for a line feed at index
+	// 0x0a of the line.
+	Mnemonic bool
+
+	// Strip7 clears the high bit of each byte before it is shown in the
+	// character (and Mnemonic) column, for 7-bit serial data with a
+	// parity bit, while the hex column still shows the byte as it is.
+	// Latin1 is ignored with it, and it is ignored with EBCDIC.
+	Strip7 bool
+
+	// UTF8 decodes the character column of a text (or CSV) dump as
+	// UTF-8: a printable character of several bytes is shown under its
+	// first byte with a "_" for each byte after it, so the column still
+	// has one place per byte. Invalid bytes, and a character cut off by
+	// the end of a line, are shown as dots. Latin1 is ignored with it,
+	// and it is ignored with EBCDIC and Strip7. The JSON output is
+	// unchanged.
+	UTF8 bool
+
+	// NoASCII leaves the character column, and the separator before it,
+	// out of a text dump, so that each line ends with its last hex
+	// digits. Mnemonic is ignored with it. The JSON output keeps its
+	// ascii field.
+	NoASCII bool
+
+	// Separator, when set, goes between the columns of a text line in
+	// place of " : " and "  : ", for output that is easy to split, such
+	// as on a tab. The hex column then has no space before its first
+	// byte and a short last line is not padded. Reverse only reads the
+	// usual separators.
+	Separator string
+
+	// RightOffset adds a column at the end of each text line, after the
+	// character column (or the hex column with NoASCII) and before any
+	// mnemonics, with the offset just after the line's last byte, which
+	// is the offset of the next line. It is laid out as the offset
+	// column is. With LineNumbers it repeats the line's own number.
+	RightOffset bool
+
+	// ASCIIFirst puts the character column of a text line before the
+	// hex column, "0000 : hello : 68 65 ...", with the character column
+	// padded so that the hex columns line up. It is ignored with
+	// NoASCII, and Reverse cannot read it.
+	ASCIIFirst bool
+
+	// NoOffset leaves the offset column, and the separator after it, out
+	// of a text dump, so that each line starts with its first hex byte
+	// (or its characters with ASCIIFirst). With NoASCII too only the hex
+	// bytes are left. The header, the highlight carets, the record rules
+	// and the padding lines of Trim line up with what is left, and a
+	// RightOffset is still shown. The JSON, CSV and Template outputs
+	// keep their offsets, and Reverse cannot read the dump.
+	NoOffset bool
+
+	// LineCRC adds a column after the hex and character columns, and
+	// before any RightOffset, with the CRC-16/CCITT-FALSE of the line's
+	// bytes in 4 hex digits. Lines with the same bytes have the same
+	// CRC, whatever their offsets, so two dumps can be compared by it.
+	// Reverse still reads the line.
+	LineCRC bool
+
+	// Delta adds a column after the hex and character columns, before
+	// any CRC, with the signed difference between each byte and the one
+	// before it in the stream, modulo 256 (so from -128 to +127). The
+	// first byte dumped has none and is left blank. It is not used with
+	// Highlight, Grep or a Step over 1, whose lines are not printed in
+	// the order of the stream.
+	Delta bool
+
+	// Template, when set, lays out each line of a text dump instead of
+	// the usual columns. It is run with .Offset, .Bytes, .Address, .Hex
+	// and .ASCII (also .Ascii) and each line ends with a newline. Lines
+	// are not squeezed or trimmed with it, and Header and Highlight are
+	// not used. An error running it stops the dump and is returned.
+	Template *template.Template
+
+	// Squeeze replaces a run of lines identical to the line before them
+	// with a single "*" line, as the classic hexdump and od tools do.
+	// The last line of the stream is always printed so the end offset
+	// is visible.
+	Squeeze bool
+
+	// SqueezeCount moves the squeeze marker to the end of the run and
+	// has it give the number of bytes left out, such as
+	// "* (0x1000 bytes identical)". The line printed after the marker is
+	// not counted.
+	SqueezeCount bool
+
+	// Trim replaces a run of lines that are all 0x00, or all 0x20,
+	// padding with a single line giving the length of the run, such as
+	// "0200 :  [768 bytes of 00]". Unlike Squeeze, the lines do not have
+	// to follow a line of the same bytes, and a run of one padding line
+	// is collapsed too. Squeeze still applies to the other lines.
+	Trim bool
+
+	// Step, when more than 1, samples the dump: the first line is
+	// printed, the next Step-1 left out, and so on, with the offsets
+	// still those of the stream so that the gaps show. The last line of
+	// the stream is always printed. Squeeze then applies to the lines
+	// that are printed. Trim is not used with it, as the length of a run
+	// of padding cannot be known from the lines that are printed.
+	Step int
 
-	var displayWidth int
-	wide := flag.Bool("w", false, "32 byte wide display (cannot use with '-x')")
-	extraWide := flag.Bool("x", false, "64 byte wide display (cannot use with '-w'")
+	// Record, when not zero, splits the stream into records of that
+	// many bytes, counted from offset 0 (or from where the dump starts
+	// with Relative). Each record starts on a new line and a dashed
+	// rule, as wide as a full line, is printed between two records in
+	// the text layout, but not with Highlight or Grep. The offsets run
+	// on over the records. A squeeze or a run of padding does not carry
+	// on from one record into the next. LineNumbers is not used with
+	// it, as the lines are not all the same width.
+	Record uint64
 
-	flag.Parse()
-	args := flag.Args()
+	// TimeAt, when set with Record, decodes the Unix time at its place
+	// in each record and shows it at the end of the line where the time
+	// ends, as an RFC 3339 UTC time or "invalid" if its year is not from
+	// 0 to 9999. That line is never squeezed or trimmed. It is ignored
+	// if the field is not 4 or 8 bytes or does not fit in a record, and
+	// with Highlight, Grep or a Step over 1.
+	TimeAt *TimeField
 
-	if *wide && *extraWide {
-		fmt.Fprintf(os.Stderr, "Error: Wide and Extra wide options are mutually exclusive\n")
-		os.Exit(1)
+	// BufferSize is the number of bytes asked for in each read of the
+	// stream, 4096 if it is not more than zero. A bigger buffer means
+	// fewer reads of a large file. It does not change the dump, as lines
+	// are carried over from one read to the next.
+	BufferSize int
+
+	// Skip is the number of bytes to skip before dumping. The offset
+	// column starts at Skip so the addresses stay meaningful.
+	Skip uint64
+
+	// Relative counts the offsets from where the dump starts, after Skip
+	// and Find, rather than from the start of the stream: the first line
+	// is at offset 0 and full width, and the Line, JSON and CSV offsets
+	// are relative too. The bytes are still skipped.
+	Relative bool
+
+	// Find, when set, moves on from Skip to the first place its bytes
+	// appear and starts the dump at that offset. ErrNotFound is
+	// returned, and nothing dumped, if they do not.
+	Find []byte
+
+	// Length, when not zero, stops the dump after that many bytes,
+	// counted from the Skip offset.
+	Length uint64
+
+	// Tee, when set, is also written every byte that is dumped (after
+	// Skip and Length), for example to a hash.
+	Tee io.Writer
+
+	// Totals, when set, has the bytes and lines of the dump added to it
+	// as they are dumped, so one Totals can add up several dumps.
+	Totals *Totals
+
+	// Header prints a ruler line above a text dump with the index of
+	// each byte (or word) in a line over the hex column, if there are
+	// any lines.
+	Header bool
+
+	// Magic prints a line such as "Type: PNG image" above a text dump
+	// with a guess at the format of the data, from the magic number in
+	// the first buffer read ("Type: unknown" if there is none). Nothing
+	// is printed for empty input.
+	Magic bool
+
+	// Highlight marks every match of its bytes (overlapping ones
+	// included) in a text dump: in reverse video if Color is set,
+	// otherwise with a line of "^" under each line holding a match.
+	// Lines are not squeezed when highlighting, as that could hide a
+	// match.
+	Highlight []byte
+
+	// Grep, when set, only prints the lines whose bytes hold the Grep
+	// bytes. A "--" line separates the groups of lines that are not next
+	// to each other (in the text layout). Lines are not squeezed with
+	// Grep.
+	Grep []byte
+
+	// Context is the number of lines printed either side of each line
+	// Grep matches, as grep -C does.
+	Context int
+}
+
+// dumper holds the state of a dump that has to be carried from one
+// buffer read to the next
+type dumper struct {
+	w            io.Writer
+	writeLine    func(line Line) // takes each line that is not squeezed
+	print        func(line Line) // prints a line in the output format
+	encoder      *json.Encoder
+	table        bool // lines are written as CSV rows
+	offsetFormat string
+	lineNumbers  bool   // the offset column holds line numbers
+	firstLine    uint64 // offset / width of the first line
+	lineBuffer   []byte // reused to build each line of output
+	hexDigits    string
+	displayWidth int
+	hexWidth     int
+	group        int
+	word         int      // bytes per word of the hex column
+	littleEndian bool     // words are shown with their last byte first
+	binary       bool     // bytes are shown as 8 binary digits
+	byteType     ByteType // how each byte of the value column is shown
+	byteDigits   int      // width taken by each byte, 2 to 8
+	color        bool
+	chars        charMapper
+	mnemonic     bool
+	noASCII      bool
+	rightOffset  bool   // the end offset is shown after the last column
+	asciiFirst   bool   // the ASCII column comes before the hex column
+	noOffset     bool   // the offset column is left out
+	hexLead      bool   // a space goes before the first hex byte
+	lineCRC      bool   // the CRC of each line is shown after its bytes
+	delta        bool   // the difference from the byte before is shown
+	offsetSep    string // between the offset and hex columns
+	asciiSep     string // between the hex and ASCII columns
+	separated    bool   // a Separator was given
+	template     *template.Template
+	squeeze      bool
+	squeezeCount bool // the squeeze marker gives the bytes left out
+	trim         bool
+	header       bool   // the ruler is still to be printed
+	magic        bool   // the file type is still to be printed
+	canonical    bool   // the end offset is printed after the dump
+	endOffset    uint64 // offset just past the last line seen, 0 if none
+
+	totals *Totals // the counts of the dump, if wanted
+
+	record uint64 // bytes in each record, 0 for none
+	ruled  bool   // a rule is printed between the records
+	ruling bool   // the rule is still to be printed before the next line
+
+	step          int  // only every step'th line is printed
+	stepIndex     int  // lines seen so far
+	lastDropped   Line // the last line left out by sampling
+	dropped       bool // the last line seen was left out
+	droppedRuling bool // a rule was due before lastDropped
+
+	pending       []byte // a line that is not yet complete
+	pendingOffset uint64 // offset of the first byte of pending
+
+	previous   []byte // the last line seen, used for squeezing
+	lastOffset uint64 // offset of the last line seen
+	squeezing  bool   // a "*" has been printed for the current run
+	squeezed   uint64 // bytes left out from the current run
+
+	lastByte   byte // the last byte of the stream seen so far
+	lastKnown  bool // a byte has been seen
+	deltaByte  byte // the byte before the line being formatted
+	deltaKnown bool // there is a byte before the line
+
+	timeAt    *TimeField // the time field of each record, nil if none
+	timeStart uint64     // stream offset of the field being collected
+	timeBytes [8]byte    // the bytes of the field seen so far
+	timeHave  int        // how many of them there are
+	timeText  string     // the decoded time for the line, "" if none
+
+	padding   bool   // in a run of padding lines
+	padByte   byte   // the byte the run is made of
+	padOffset uint64 // offset of the start of the run
+	padLength uint64 // bytes in the run
+
+	highlight []byte // pattern whose matches are highlighted
+	spans     []span // matches that are not yet printed, in order
+	tail      []byte // the end of the stream, for matches across reads
+	held      []Line // lines that a match could still reach
+	decided   uint64 // every match covering a byte before this is known
+
+	grep       []byte     // only lines holding this are printed
+	context    int        // lines printed either side of a grep match
+	emit       func(Line) // where the lines grep keeps are passed on
+	output     func(Line) // prints a line after the grep separator
+	before     []Line     // lines kept in case a match follows
+	after      int        // lines still to print after a match
+	printed    bool       // a grep line has been printed
+	nextOffset uint64     // offset after the last grep line printed
+
+	stopped bool  // the consumer of the lines wants no more
+	failure error // why a line could not be printed, which stops the dump
+}
+
+// Dump dumps the content of an IO stream in hex and ASCII format. The
+// function reads from the stream r and writes the hex & ASCII
+// characters to the writer w. Reading stops at EOF, any other read
+// error is returned. As io.Reader allows, a read can return bytes along
+// with an error (io.EOF included); the bytes are always dumped before
+// the error is acted on.
+//
+// All the state of a dump lives in the call: every call starts its
+// offset column again at Skip (zero by default) and nothing, such as
+// the last line kept for squeezing, is carried over from an earlier
+// call.
+func Dump(w io.Writer, r io.Reader, opts Options) error {
+
+	return DumpContext(context.Background(), w, r, opts)
+}
+
+// DumpContext is Dump with a context to cancel it. The context is
+// checked before each read of r, so a dump stops within one buffer of
+// ctx being cancelled; a read that is blocked is not interrupted. When
+// cancelled the lines already formatted are written to w and ctx.Err()
+// is returned.
+func DumpContext(ctx context.Context, w io.Writer, r io.Reader, opts Options) error {
+
+	layout, opts, err := compileLayout(opts)
+	if err != nil {
+		return err
+	}
+
+	return layout.run(ctx, w, r, opts)
+}
+
+// compileLayout checks opts and works out the layout of a dump with
+// them, which run copies for each stream it dumps. The options are
+// returned as the stream is to be read with them, which for Canonical
+// are not the ones given.
+func compileLayout(opts Options) (*dumper, Options, error) {
+
+	if opts.Width > MaxWidth {
+		return nil, opts, ErrWidth
+	}
+
+	if opts.Format == Canonical {
+		opts = canonicalOptions(opts)
+	}
+
+	d := newDumper(nil, opts)
+
+	switch opts.Format {
+	case JSON, CSV:
+		d.squeeze = false
+		d.trim = false
+	case Canonical:
+		d.canonical = true
+	default:
+		if opts.Template != nil {
+			d.template = opts.Template
+			d.squeeze = false
+			d.trim = false
+			break
+		}
+
+		d.header = opts.Header
+		d.magic = opts.Magic
+		d.ruled = d.record > 0
+		if opts.TimeAt != nil && d.record > 0 && d.step <= 1 && opts.TimeAt.fits(d.record) {
+			d.timeAt = opts.TimeAt
+		}
+		if len(opts.Highlight) > 0 {
+			d.highlight = opts.Highlight
+			d.squeeze = false
+			d.trim = false
+			d.ruled = false
+			d.delta = false
+			d.timeAt = nil
+		}
+	}
+
+	if len(opts.Grep) > 0 {
+		d.grep = opts.Grep
+		d.context = max(opts.Context, 0)
+		d.squeeze = false
+		d.trim = false
+		d.ruled = false
+		d.delta = false
+		d.timeAt = nil
 	}
 
+	return d, opts, nil
+}
+
+// run dumps r to w with a copy of the layout, so that the layout is
+// left as it was for the next stream. opts are those compileLayout
+// returned with it.
+func (layout *dumper) run(ctx context.Context, w io.Writer, r io.Reader, opts Options) error {
+
+	d, out := layout.start(w, opts)
+
+	return d.end(out, d.dump(ctx, r, opts))
+}
+
+// start returns a copy of the layout, writing to w through the buffer
+// it also returns, with its lines passed to the print functions of the
+// copy for the Format of opts
+func (layout *dumper) start(w io.Writer, opts Options) (*dumper, *bufio.Writer) {
+
+	// Writing each line straight to w would mean a system call per
+	// line when w is STDOUT
+	out := bufio.NewWriter(w)
+
+	d := new(dumper)
+	*d = *layout
+	d.w = out
+
 	switch {
-	case *wide:
-		displayWidth = wideWidth
-	case *extraWide:
-		displayWidth = extraWideWidth
+	case opts.Format == JSON:
+		d.encoder = json.NewEncoder(d.w)
+		d.encoder.SetEscapeHTML(false)
+		d.print = d.printJSON
+	case d.canonical:
+		d.print = d.printCanonical
+	case opts.Format == CSV:
+		d.table = true
+		d.printCSVHeader()
+		d.print = d.printCSV
+	case d.template != nil:
+		d.print = d.printTemplate
 	default:
-		displayWidth = normalWidth
+		d.print = d.printLine
 	}
 
-	numberOfFiles := flag.NArg()
+	// A line goes through grep, then is held for highlighting, then
+	// printed. The "--" between grep groups is written at the print
+	// stage so it stays in order with any held lines.
+	if d.grep != nil {
+		d.output = d.print
+		d.print = d.grepPrint
+	}
 
-	if numberOfFiles == 0 {
-		hexdump(os.Stdin, hex64Bits, displayWidth)
-	} else {
-		for i := range args {
-			file := args[i]
-
-			if fh, fileScale, err := openRegularFile(file); err != nil {
-				fmt.Fprintf(os.Stderr, "\nWarning: Skipping file: %s\n", err)
-			} else {
-				defer fh.Close()
-				hexdump(fh, fileScale, displayWidth)
+	d.writeLine = d.print
+	if d.highlight != nil {
+		d.writeLine = d.holdLine
+	}
+	if d.grep != nil {
+		d.emit = d.writeLine
+		d.writeLine = d.grepLine
+	}
+
+	return d, out
+}
+
+// end flushes what the dump has written to out, and returns err from
+// the dump or else the error flushing it
+func (d *dumper) end(out *bufio.Writer, err error) error {
+
+	if flushErr := out.Flush(); err == nil {
+		err = flushErr
+	}
+	return err
+}
+
+// newDumper sets up a dumper writing to w with the layout from opts.
+// The caller picks how lines are written by setting writeLine.
+func newDumper(w io.Writer, opts Options) *dumper {
+
+	displayWidth := opts.Width
+	if displayWidth <= 0 {
+		displayWidth = NormalWidth
+	}
+
+	word := opts.Word
+	if word < 1 || opts.Type != HexByte {
+		word = 1
+	}
+	binary := opts.Binary && opts.Type == HexByte
+	noASCII := opts.NoASCII || opts.Type == CharByte
+
+	// A group gap can only go between words
+	group := opts.Group
+	if group < 0 {
+		group = 0
+	}
+	if group%word != 0 {
+		group += word - group%word
+	}
+
+	byteDigits := typeDigits(opts.Type, binary)
+
+	// The lines of a record dump are not all the same width, so they
+	// cannot be counted from the offset
+	lineNumbers := opts.LineNumbers && opts.Record == 0
+
+	radix := opts.Radix
+	if lineNumbers {
+		radix = Decimal
+	}
+
+	d := &dumper{
+		w:            w,
+		offsetFormat: caseFormat(columnFormat(opts, radix), opts.Upper),
+		lineNumbers:  lineNumbers,
+		hexDigits:    lowerHexDigits,
+		displayWidth: displayWidth,
+		hexWidth:     hexColumnWidth(displayWidth, group, word, byteDigits),
+		group:        group,
+		word:         word,
+		littleEndian: opts.LittleEndian,
+		binary:       binary,
+		byteType:     opts.Type,
+		byteDigits:   byteDigits,
+		color:        opts.Color,
+		chars:        newCharMapper(opts),
+		mnemonic:     opts.Mnemonic && !noASCII,
+		noASCII:      noASCII,
+		rightOffset:  opts.RightOffset,
+		asciiFirst:   opts.ASCIIFirst && !noASCII,
+		noOffset:     opts.NoOffset,
+		lineCRC:      opts.LineCRC,
+		delta:        opts.Delta && opts.Step <= 1,
+		squeeze:      opts.Squeeze,
+		squeezeCount: opts.SqueezeCount,
+		trim:         opts.Trim && opts.Step <= 1,
+		step:         opts.Step,
+		record:       opts.Record,
+		totals:       opts.Totals,
+		offsetSep:    offsetSeparator,
+		asciiSep:     asciiSeparator,
+	}
+
+	if opts.Separator != "" {
+		d.offsetSep = opts.Separator
+		d.asciiSep = opts.Separator
+		d.separated = true
+	}
+
+	// The space before the first hex byte sets it off from the offset
+	// column, so a line with none starts with the byte itself
+	d.hexLead = !d.separated
+	if d.noOffset && !d.asciiFirst && !d.separated {
+		d.hexLead = false
+		d.hexWidth--
+	}
+
+	if opts.Upper {
+		d.hexDigits = upperHexDigits
+	}
+
+	return d
+}
+
+// dump reads r to the end, passing each line to writeLine. It stops
+// early, without reading any more of r, once stopped is set or ctx is
+// cancelled.
+func (d *dumper) dump(ctx context.Context, r io.Reader, opts Options) error {
+
+	buffer := make([]byte, readBufferSize(opts))
+
+	r, offset, err := limitInput(r, opts)
+	if err != nil {
+		return err
+	}
+	d.firstLine = offset / uint64(d.displayWidth)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		bufferRead, err := r.Read(buffer)
+
+		// A reader can return the last of its data along with the
+		// error (gzip does), so the bytes are used before err is
+		if bufferRead > 0 {
+			if d.magic {
+				d.printMagic(buffer[:bufferRead])
+			}
+			offset = d.formatBuffer(buffer, bufferRead, offset)
+		}
+
+		if d.stopped {
+			return d.failure
+		}
+
+		if err != nil {
+			d.finish()
+			if d.failure != nil {
+				return d.failure
 			}
+			if err == io.EOF {
+				err = nil
+			}
+			return err
 		}
 	}
 }
 
-// hexdump dump the content of an IO stream in hex and ASCII format
-//		The function reads io from an inout stream and writes the
-//		hex & ASCII characters to STDOUT.
-//		The output format is:
-//			<File Offset>   <hex> ... <hex>  : <printable ASCII chars>
+// DumpBytes dumps data, as Dump would dump a reader of it, without
+// copying it into a read buffer. The lines are cut from data itself, so
+// it suits a file mapped into memory. Skip, Find and Length pick out
+// the bytes as they do for a stream.
+func DumpBytes(w io.Writer, data []byte, opts Options) error {
 
-func hexdump(fh *os.File, fileScale string, displayWidth int) {
+	return DumpBytesContext(context.Background(), w, data, opts)
+}
 
-	buffer := make([]byte, bufferSize)
-	var offset uint64
+// DumpBytesContext is DumpBytes with a context to cancel it. The context
+// is checked before each buffer's worth of data, as DumpContext checks
+// it before each read.
+func DumpBytesContext(ctx context.Context, w io.Writer, data []byte, opts Options) error {
 
-	for {
-		if bufferRead, err := fh.Read(buffer); err == nil {
-			offset = formatBuffer(buffer, bufferRead, fileScale, offset, displayWidth)
-		} else {
-			if err != io.EOF {
-				fmt.Println("Error:", err)
+	layout, opts, err := compileLayout(opts)
+	if err != nil {
+		return err
+	}
+
+	d, out := layout.start(w, opts)
+
+	return d.end(out, d.dumpBytes(ctx, data, opts))
+}
+
+// dumpBytes is dump for data held in memory, passing the lines cut
+// from data a buffer's worth at a time
+func (d *dumper) dumpBytes(ctx context.Context, data []byte, opts Options) error {
+
+	if opts.Skip > uint64(len(data)) {
+		return ErrSkipPastEnd
+	}
+	data = data[opts.Skip:]
+	offset := opts.Skip
+
+	if opts.Find != nil {
+		i := bytes.Index(data, opts.Find)
+		if i < 0 {
+			return ErrNotFound
+		}
+		data = data[i:]
+		offset += uint64(i)
+	}
+
+	if opts.Length > 0 && opts.Length < uint64(len(data)) {
+		data = data[:opts.Length]
+	}
+
+	if opts.Relative {
+		offset = 0
+	}
+	d.firstLine = offset / uint64(d.displayWidth)
+
+	size := readBufferSize(opts)
+	for len(data) > 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		buffer := data[:min(size, len(data))]
+		data = data[len(buffer):]
+
+		if opts.Tee != nil {
+			if _, err := opts.Tee.Write(buffer); err != nil {
+				return err
 			}
-			return
+		}
+		if d.magic {
+			d.printMagic(buffer)
+		}
+		offset = d.formatBuffer(buffer, len(buffer), offset)
+
+		if d.stopped {
+			return d.failure
 		}
 	}
+
+	d.finish()
+
+	return d.failure
 }
 
-// formatBuffer takes the content of a buffer and prints. The code produces
-// 	an output formatted as follows:
-//
-//	<offset hex address>   <16 hex bytes>  <ASCii characters>
+// DumpString dumps a byte slice and returns the dump as a string. It is
+// Dump with the reader and writer set up for the caller. Empty input
+// gives an empty string, not a line with a zero offset. The slice
+// cannot fail to read so no error is returned; a Skip past the end of
+// data, which Dump reports as ErrSkipPastEnd, also gives an empty
+// string.
+func DumpString(data []byte, opts Options) string {
+
+	var buffer bytes.Buffer
+	Dump(&buffer, bytes.NewReader(data), opts)
+
+	return buffer.String()
+}
+
+// readBufferSize returns the size of the buffer to read the stream
+// into, from the BufferSize option
+func readBufferSize(opts Options) int {
 
-func formatBuffer(buffer []byte,
-	bytesInBuffer int,
-	fileScale string,
-	position uint64,
-	displayWidth int) uint64 {
+	if opts.BufferSize <= 0 {
+		return bufferSize
+	}
+	return opts.BufferSize
+}
 
-	var hexDigits string
-	var chrDigits string
-	linePosition := position
+// limitInput applies the Skip, Find, Length and Tee options to the
+// stream r, returning the reader the dump is to be read from and the
+// offset in r that it starts at, which is 0 with Relative
+func limitInput(r io.Reader, opts Options) (io.Reader, uint64, error) {
 
-	// Dynamically build the output format string for "Printf"
-	outputFormat := fileScale + " : " + fmt.Sprintf("%%-%ds", 3*displayWidth) + "  : %s\n"
-	for i := 0; i < bytesInBuffer; i++ {
+	offset := opts.Skip
 
-		widthCounter := position % uint64(displayWidth)
-		if widthCounter == 0 && i > 0 {
-			fmt.Printf(outputFormat, linePosition, hexDigits, chrDigits)
-			hexDigits = ""
-			chrDigits = ""
-			linePosition = position
+	if opts.Skip > 0 {
+		if err := skip(r, opts.Skip); err != nil {
+			return nil, 0, err
 		}
+	}
 
-		hexDigits = fmt.Sprintf("%s %2.2x", hexDigits, buffer[i])
+	if opts.Find != nil {
+		var err error
+		if r, offset, err = find(r, opts.Find, offset); err != nil {
+			return nil, 0, err
+		}
+	}
 
-		if isPrintable(buffer[i]) {
-			chrDigits = fmt.Sprintf("%s%c", chrDigits, buffer[i])
-		} else {
-			chrDigits = chrDigits + "."
+	if opts.Length > 0 {
+		r = io.LimitReader(r, int64(opts.Length))
+	}
+
+	if opts.Tee != nil {
+		r = io.TeeReader(r, opts.Tee)
+	}
+
+	if opts.Relative {
+		offset = 0
+	}
+
+	return r, offset, nil
+}
+
+// skip moves the stream r forward by n bytes. A stream that can seek is
+// seeked, anything else (such as STDIN from a pipe) has the bytes read
+// and discarded. Either way ErrSkipPastEnd is returned if the stream is
+// shorter than n bytes.
+func skip(r io.Reader, n uint64) error {
+
+	if seeker, ok := r.(io.Seeker); ok {
+		if current, err := seeker.Seek(0, io.SeekCurrent); err == nil {
+			end, err := seeker.Seek(0, io.SeekEnd)
+			if err != nil {
+				return err
+			}
+			if n > uint64(end-current) {
+				return ErrSkipPastEnd
+			}
+
+			_, err = seeker.Seek(current+int64(n), io.SeekStart)
+			return err
 		}
+	}
 
-		position++
+	if _, err := io.CopyN(io.Discard, r, int64(n)); err != nil {
+		if err == io.EOF {
+			return ErrSkipPastEnd
+		}
+		return err
 	}
 
-	fmt.Printf(outputFormat, linePosition, hexDigits, chrDigits)
-	return position
+	return nil
 }
 
-// isPrintable checks to see if a character is a printable
-// character. This is based on the "C" code. It will probably
-// be converted into a lambda function - wish this had "macros"
+// find reads r up to the first match of pattern, returning a reader
+// that starts with the match and the offset of the match. r is at
+// offset when find is called. The last len(pattern)-1 bytes of each
+// read are kept for the next one so that a match split across two reads
+// is still found.
+func find(r io.Reader, pattern []byte, offset uint64) (io.Reader, uint64, error) {
 
-func isPrintable(ch byte) (printable bool) {
+	buffer := make([]byte, bufferSize+len(pattern))
+	kept := 0
 
-	if ch >= chSpace && ch < chDel {
-		return true
+	for {
+		bufferRead, err := r.Read(buffer[kept:])
+		data := buffer[:kept+bufferRead]
+
+		if i := bytes.Index(data, pattern); i >= 0 {
+			return io.MultiReader(bytes.NewReader(data[i:]), r), offset + uint64(i), nil
+		}
+
+		if err != nil {
+			if err == io.EOF {
+				err = ErrNotFound
+			}
+			return nil, 0, err
+		}
+
+		kept = len(pattern) - 1
+		if kept > len(data) {
+			kept = len(data)
+		}
+		offset += uint64(len(data) - kept)
+		copy(buffer, data[len(data)-kept:])
 	}
+}
 
-	return false
+// hexColumnWidth returns the width, in characters, of the hex column
+// for a full line. Each byte takes byteDigits characters, each word
+// (a byte unless words are used) a space before it, plus one for
+// every group separator.
+func hexColumnWidth(displayWidth int, group int, word int, byteDigits int) int {
+
+	words := (displayWidth + word - 1) / word
+	width := byteDigits*displayWidth + words
+	if group > 0 {
+		width += (displayWidth - 1) / group
+	}
+
+	return width
+}
+
+// caseFormat returns the format string with its hex verbs in lower
+// case, unless upper is set
+func caseFormat(format string, upper bool) string {
+
+	if upper {
+		return strings.ReplaceAll(format, "x", "X")
+	}
+	return strings.ReplaceAll(format, "X", "x")
+}
+
+// offsetFormat returns the "Printf" format string for an offset of
+// the given scale and radix. The field is wide enough for the largest
+// address of the scale.
+func offsetFormat(scale Scale, radix Radix) string {
+
+	formats := [...][3]string{
+		Hex:     {hex64Bits, hex16Bits, hex32Bits},
+		Decimal: {dec64Bits, dec16Bits, dec32Bits},
+		Octal:   {oct64Bits, oct16Bits, oct32Bits},
+	}
+
+	if radix < Hex || radix > Octal {
+		radix = Hex
+	}
+	if scale < Scale64 || scale > Scale32 {
+		scale = Scale64
+	}
+
+	return formats[radix][scale]
+}
+
+// address returns the number shown in the offset column for a line
+// starting at offset: the offset itself, or the number of the line
+// counting from 1 with LineNumbers. The lines are on width boundaries
+// of the stream, so a short first line after a Skip is line 1 and the
+// next line 2.
+func (d *dumper) address(offset uint64) uint64 {
+
+	if d.lineNumbers {
+		return offset/uint64(d.displayWidth) - d.firstLine + 1
+	}
+
+	return offset
+}
+
+// FormatOffset returns offset as the offset column of a text dump with
+// opts shows it, in its Radix and width and case, for a line printed
+// alongside the dump to line up with it. LineNumbers is not used, as a
+// line number needs the whole dump to count from.
+func FormatOffset(offset uint64, opts Options) string {
+
+	return fmt.Sprintf(caseFormat(columnFormat(opts, opts.Radix), opts.Upper), offset)
+}
+
+// columnFormat returns the "Printf" format string for the offset
+// column, offsetFormat for the scale unless OffsetDigits is set
+func columnFormat(opts Options, radix Radix) string {
+
+	if opts.OffsetDigits <= 0 {
+		return offsetFormat(opts.Scale, radix)
+	}
+
+	verbs := [...]string{Hex: "X", Decimal: "d", Octal: "o"}
+	if radix < Hex || radix > Octal {
+		radix = Hex
+	}
+
+	return fmt.Sprintf("%%%d.%d%s", opts.OffsetDigits, opts.OffsetDigits, verbs[radix])
 }
 
-// openRegularFile will only allow a regular file to be opened for reading.
-// 		The function returns a file handle to a requested file only
-// 		if the following conditions are cleared:
+// formatBuffer takes the content of a buffer and prints it to w, in
+// lines laid out as follows:
 //
-//		1. The file is a regular file (links are allowed to regular files)
-//		2. The user has permissions to read the file
+//	<offset hex address>   <16 hex bytes>  <ASCii characters>
+//
+// The buffer is split into lines on displayWidth boundaries of the
+// stream position, or of the record, and each line is passed to
+// formatLine. A line that is not complete at the end of the buffer is
+// kept in pending for the next buffer, so how the stream is split into
+// reads does not change the dump. The offset after the buffer is
+// returned.
+func (d *dumper) formatBuffer(buffer []byte, bytesInBuffer int, position uint64) uint64 {
+
+	data := buffer[:bytesInBuffer]
 
-func openRegularFile(filename string) (fh *os.File, fileSizeScale string, err error) {
+	if d.highlight != nil {
+		d.scan(data, position)
+	}
 
-	err = nil
-	fh = nil
+	for len(data) > 0 {
 
-	fileInfo, err := os.Stat(filename)
-	if err != nil {
+		room := d.lineRoom(position)
+		n := room
+		if n > len(data) {
+			n = len(data)
+		}
+
+		switch {
+		case len(d.pending) == 0 && n == room:
+			d.formatLine(Line{Offset: position, Bytes: data[:n]})
+		case len(d.pending) == 0:
+			d.pendingOffset = position
+			d.pending = append(d.pending, data[:n]...)
+		default:
+			d.pending = append(d.pending, data[:n]...)
+			if n == room {
+				d.formatLine(Line{Offset: d.pendingOffset, Bytes: d.pending})
+				d.pending = d.pending[:0]
+			}
+		}
+
+		position += uint64(n)
+		data = data[n:]
+	}
+
+	return position
+}
+
+// formatLine prints a single line of the dump, unless squeezing is
+// enabled and the line is a duplicate of the one before it
+func (d *dumper) formatLine(line Line) {
+
+	if d.totals != nil {
+		d.totals.Bytes += uint64(len(line.Bytes))
+		d.totals.Lines++
+	}
+	d.endOffset = line.Offset + uint64(len(line.Bytes))
+
+	if d.delta {
+		d.noteDelta(line)
+	}
+	if d.timeAt != nil {
+		d.noteTime(line)
+	}
+
+	if d.record > 0 {
+		defer d.endRecord(line)
+	}
+
+	if d.step > 1 && d.dropLine(line) {
 		return
 	}
 
-	fileMode := fileInfo.Mode()
-	if !fileMode.IsRegular() {
-		errorMsg := fmt.Sprintf("open %s: It's not a regular file", filename)
-		err = errors.New(errorMsg)
+	if d.ruling {
+		d.printRule()
+	}
+
+	// A line giving a time is always printed, so the time is seen
+	if d.trim {
+		if d.timeText == "" && d.addPadding(line) {
+			return
+		}
+		d.endPadding()
+	}
+
+	if d.squeeze && d.timeText == "" && d.previous != nil && bytes.Equal(line.Bytes, d.previous) {
+		if !d.squeezing && !d.squeezeCount {
+			fmt.Fprintln(d.w, squeezeMarker)
+		}
+		d.squeezing = true
+		d.squeezed += uint64(len(line.Bytes))
+		d.lastOffset = line.Offset
 		return
 	}
 
+	d.endSqueeze(0)
+	d.writeLine(line)
+	d.squeezing = false
+
+	if d.squeeze {
+		d.previous = append(d.previous[:0], line.Bytes...)
+		d.lastOffset = line.Offset
+	}
+}
+
+// finish is called at the end of the stream. It prints the last line
+// if it was not complete and, if the dump ended in a run of squeezed
+// lines, prints the final line to mark the end. Any lines held back
+// for highlighting are printed.
+func (d *dumper) finish() {
+
+	if len(d.pending) > 0 {
+		d.formatLine(Line{Offset: d.pendingOffset, Bytes: d.pending})
+		d.pending = d.pending[:0]
+	}
+
+	d.endSample()
+	d.endPadding()
+
+	// hexdump -C marks the end with its offset rather than the last
+	// line of a squeezed run
+	if d.canonical {
+		if d.endOffset > 0 {
+			fmt.Fprintf(d.w, "%08x\n", d.endOffset)
+		}
+		d.squeezing = false
+	}
+
+	if d.squeezing {
+		d.endSqueeze(uint64(len(d.previous)))
+		d.writeLine(Line{Offset: d.lastOffset, Bytes: d.previous})
+		d.squeezing = false
+	}
+
+	if d.highlight != nil {
+		d.decided = ^uint64(0)
+		d.release()
+	}
+}
+
+// endSqueeze prints the SqueezeCount marker for the run of squeezed
+// lines that is ending, if there is one. shown is the part of the run
+// that is printed after the marker, and not counted. A run that is all
+// shown gets no marker.
+func (d *dumper) endSqueeze(shown uint64) {
+
+	if d.squeezeCount && d.squeezing && d.squeezed > shown {
+		fmt.Fprintf(d.w, squeezeCounted+"\n", d.squeezed-shown)
+	}
+	d.squeezed = 0
+}
+
+// printLine formats the offset, hex and ASCII columns of one line. The
+// line is built straight into lineBuffer, with the hex digits taken
+// from a lookup table, and written in one go.
+func (d *dumper) printLine(line Line) {
+
+	if d.header {
+		d.printHeader()
+	}
+
+	buffer := d.appendOffset(d.lineBuffer[:0], line.Offset)
+
+	var visible int
+	if d.asciiFirst {
+		buffer = d.appendASCII(buffer, line)
+		buffer = d.padASCII(buffer, line)
+		buffer = append(buffer, d.hexSeparator()...)
+		buffer, visible = d.appendHex(buffer, line)
+	} else {
+		buffer, visible = d.appendHex(buffer, line)
+		if !d.noASCII || d.rightOffset || d.lineCRC || d.delta {
+			buffer = d.padHex(buffer, visible)
+		}
+		if !d.noASCII {
+			buffer = append(buffer, d.asciiSep...)
+			buffer = d.appendASCII(buffer, line)
+		}
+	}
+
+	// The right offset and the mnemonics go in columns of their own so
+	// the last column is padded out to the full width first
+	padLast := func(buffer []byte) []byte {
+		if d.asciiFirst {
+			return d.padHex(buffer, visible)
+		}
+		return d.padASCII(buffer, line)
+	}
+
+	annotations := false
+	if d.mnemonic {
+		for _, ch := range line.Bytes {
+			annotations = annotations || mnemonic(d.chars.value(ch)) != ""
+		}
+	}
+
+	if d.timeText != "" {
+		annotations = true
+	}
+
+	padded := d.noASCII
+	if d.delta {
+		buffer = padLast(buffer)
+		padded = true
+
+		buffer = append(buffer, d.offsetSep...)
+		buffer = d.appendDelta(buffer, line, d.lineCRC || d.rightOffset || annotations)
+	}
+
+	if d.lineCRC {
+		if !padded {
+			buffer = padLast(buffer)
+		}
+		padded = true
+
+		buffer = append(buffer, d.offsetSep...)
+		buffer = d.appendCRC(buffer, line)
+	}
+
+	if d.rightOffset {
+		if !padded {
+			buffer = padLast(buffer)
+		}
+		padded = true
+
+		end := line.Offset + uint64(len(line.Bytes))
+		if d.lineNumbers {
+			end = line.Offset
+		}
+		buffer = append(buffer, d.offsetSep...)
+		buffer = fmt.Appendf(buffer, d.offsetFormat, d.address(end))
+	}
+
+	if annotations {
+		if !padded {
+			buffer = padLast(buffer)
+		}
+		buffer = append(buffer, d.asciiSep...)
+
+		separator := ""
+		for i, ch := range line.Bytes {
+			if name := mnemonic(d.chars.value(ch)); d.mnemonic && name != "" {
+				buffer = append(buffer, separator...)
+				buffer = append(buffer, d.hexDigits[i>>4&0x0F], d.hexDigits[i&0x0F], '=')
+				buffer = append(buffer, name...)
+				separator = " "
+			}
+		}
+
+		if d.timeText != "" {
+			buffer = append(buffer, separator...)
+			buffer = append(buffer, d.timeText...)
+		}
+	}
+
+	buffer = append(buffer, '\n')
+	d.lineBuffer = buffer
+
+	d.w.Write(buffer)
+}
+
+// appendOffset appends the offset column, and the separator after it,
+// to buffer unless NoOffset leaves them out
+func (d *dumper) appendOffset(buffer []byte, offset uint64) []byte {
+
+	if d.noOffset {
+		return buffer
+	}
+
+	buffer = fmt.Appendf(buffer, d.offsetFormat, d.address(offset))
+	return append(buffer, d.offsetSep...)
+}
+
+// offsetWidth returns the width taken by the offset column and its
+// separator, 0 with NoOffset
+func (d *dumper) offsetWidth() int {
+
+	if d.noOffset {
+		return 0
+	}
+
+	return len(fmt.Sprintf(d.offsetFormat, 0)) + len(d.offsetSep)
+}
+
+// padHex pads the hex column of a short line, visible characters wide
+// so far, out to the full width unless there is a Separator. It goes by
+// the visible width as the colour escapes take no space on the screen.
+func (d *dumper) padHex(buffer []byte, visible int) []byte {
+
+	for ; visible < d.hexWidth && !d.separated; visible++ {
+		buffer = append(buffer, ' ')
+	}
+
+	return buffer
+}
+
+// hexSeparator returns what goes between the ASCII and hex columns
+// with ASCIIFirst. The space the hex column starts with makes up the
+// rest of a " : ".
+func (d *dumper) hexSeparator() string {
+
+	if d.separated {
+		return d.asciiSep
+	}
+
+	return " :"
+}
+
+// padASCII pads the ASCII column of a short line out to the full width,
+// unless there is a Separator or no ASCII column
+func (d *dumper) padASCII(buffer []byte, line Line) []byte {
+
+	for n := len(line.Bytes); n < d.displayWidth && !d.separated && !d.noASCII; n++ {
+		buffer = append(buffer, ' ')
+	}
+
+	return buffer
+}
+
+// appendHex appends the hex column for a line to buffer, each byte (or
+// word) with a leading space, and returns it with the number of
+// characters it takes on the screen
+func (d *dumper) appendHex(buffer []byte, line Line) ([]byte, int) {
+
+	data := line.Bytes
+	visible := 0
+	for i := 0; i < len(data); i += d.word {
+
+		if d.group > 0 && i > 0 && i%d.group == 0 {
+			buffer = append(buffer, ' ')
+			visible++
+		}
+
+		end := i + d.word
+		if end > len(data) {
+			end = len(data)
+		}
+		reversed := d.littleEndian && end-i == d.word
+
+		// A Separator takes the place of the space before the first
+		if i > 0 || d.hexLead {
+			buffer = append(buffer, ' ')
+			visible++
+		}
+
+		for j := i; j < end; j++ {
+			k := j
+			if reversed {
+				k = end - 1 - (j - i)
+			}
+			ch := data[k]
+
+			color := ""
+			switch {
+			case d.color && d.marked(line.Offset+uint64(k)):
+				color = colorHighlight
+			case d.color:
+				color = byteColor(ch, d.chars.printable(ch))
+			}
+
+			buffer = append(buffer, color...)
+			buffer = d.appendByte(buffer, ch)
+			if color != "" {
+				buffer = append(buffer, colorReset...)
+			}
+			visible += d.byteDigits
+		}
+	}
+
+	return buffer, visible
+}
+
+// appendASCII appends the character column for a line to buffer. A
+// UTF-8 character is highlighted if its first byte is.
+func (d *dumper) appendASCII(buffer []byte, line Line) []byte {
+
+	for i := 0; i < len(line.Bytes); {
+		size := d.chars.runeSize(line.Bytes[i:])
+		if d.color && d.marked(line.Offset+uint64(i)) {
+			buffer = append(buffer, colorHighlight...)
+			buffer = d.chars.appendRune(buffer, line.Bytes[i:], size)
+			buffer = append(buffer, colorReset...)
+		} else {
+			buffer = d.chars.appendRune(buffer, line.Bytes[i:], size)
+		}
+		i += size
+	}
+
+	return buffer
+}
+
+// printHeader prints a ruler line laid out as a dump line, with the
+// index within the line of each byte (or word) over the hex column. The
+// indices are in hex whatever the offset radix and wrap after ff. The
+// offset and ASCII columns are labelled. It is printed with the first
+// line, so an empty dump has none.
+func (d *dumper) printHeader() {
+
+	d.header = false
+
+	var buffer []byte
+	if !d.noOffset {
+		label := "Address"
+		offsetWidth := len(fmt.Sprintf(d.offsetFormat, 0))
+		if offsetWidth < len(label) {
+			label = "Addr"
+		}
+
+		buffer = fmt.Appendf(buffer, "%*s", offsetWidth, label)
+		buffer = append(buffer, d.offsetSep...)
+	}
+
+	var ruler []byte
+	visible := 0
+	for i := 0; i < d.displayWidth; i += d.word {
+
+		if d.group > 0 && i > 0 && i%d.group == 0 {
+			ruler = append(ruler, ' ')
+			visible++
+		}
+
+		if i > 0 || d.hexLead {
+			ruler = append(ruler, ' ')
+			visible++
+		}
+		for n := 2; n < d.byteDigits*d.word; n++ {
+			ruler = append(ruler, ' ')
+		}
+		ruler = append(ruler, d.hexDigits[i>>4&0x0F], d.hexDigits[i&0x0F])
+		visible += d.byteDigits * d.word
+	}
+
+	// The label is only padded out when a column follows it
+	asciiLabel := "ASCII"
+	if !d.separated && (d.asciiFirst || d.rightOffset || d.lineCRC || d.delta) {
+		asciiLabel = fmt.Sprintf("%-*s", d.displayWidth, asciiLabel)
+	}
+
+	if d.asciiFirst {
+		buffer = append(buffer, asciiLabel...)
+		buffer = append(buffer, d.hexSeparator()...)
+		buffer = append(buffer, ruler...)
+		if d.rightOffset || d.lineCRC || d.delta {
+			buffer = d.padHex(buffer, visible)
+		}
+	} else {
+		buffer = append(buffer, ruler...)
+		if !d.noASCII || d.rightOffset || d.lineCRC || d.delta {
+			buffer = d.padHex(buffer, visible)
+		}
+		if !d.noASCII {
+			buffer = append(buffer, d.asciiSep...)
+			buffer = append(buffer, asciiLabel...)
+		}
+	}
+
+	if d.delta {
+		buffer = append(buffer, d.offsetSep...)
+		if (d.lineCRC || d.rightOffset) && !d.separated {
+			buffer = fmt.Appendf(buffer, "%-*s", d.deltaColumnWidth(), "Delta")
+		} else {
+			buffer = append(buffer, "Delta"...)
+		}
+	}
+
+	if d.lineCRC {
+		buffer = append(buffer, d.offsetSep...)
+		if d.rightOffset && !d.separated {
+			buffer = append(buffer, "CRC "...)
+		} else {
+			buffer = append(buffer, "CRC"...)
+		}
+	}
+
+	if d.rightOffset {
+		buffer = append(buffer, d.offsetSep...)
+		buffer = append(buffer, "End"...)
+	}
+
+	d.w.Write(append(buffer, '\n'))
+}
+
+// byteColor returns the ANSI colour used for a byte in the hex column,
+// or "" if the byte is left in the default colour. printable is
+// whether the byte is shown as a character in the character column.
+func byteColor(ch byte, printable bool) string {
+
 	switch {
-	case fileInfo.Size() < int64(maxUint16):
-		fileSizeScale = hex16Bits
-	case fileInfo.Size() < int64(maxUint32):
-		fileSizeScale = hex32Bits
+	case ch == 0:
+		return colorNull
+	case printable:
+		return colorPrintable
+	case ch >= 0x80:
+		return colorHigh
 	default:
-		fileSizeScale = hex64Bits
+		return ""
+	}
+}
+
+// isPrintable checks to see if a character is a printable
+// character. This is based on the "C" code. It will probably
+// be converted into a lambda function - wish this had "macros"
+func isPrintable(ch byte) (printable bool) {
+
+	if ch >= chSpace && ch < chDel {
+		return true
 	}
 
-	fh, err = os.Open(filename)
-	return
+	return false
 }
@@ -0,0 +1,207 @@
+package hexdump
+
+/*
+	Highlighting of every match of a byte pattern in a text dump.
+
+	A match can start near the end of one read and finish in the next,
+	so a line is only printed once every match that could cover it
+	has been seen. Until then it is held back.
+
+	Edits:
+
+		2026-10-14		lc 		Created from scratch
+		2026-10-14		lc 		No ASCII carets without the ASCII column
+		2026-10-14		lc 		Carets follow the column separator
+		2026-10-14		lc 		Line numbers in the offset column
+		2026-10-14		lc 		Carets follow the column order
+		2026-10-14		lc 		Carets without the offset column
+
+	Copyright (c) 2020 NOVA Industries Limited
+
+	No warrenty implied or otherwise
+
+*/
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// span is a range of stream offsets covered by matches, end exclusive
+type span struct {
+	start uint64
+	end   uint64
+}
+
+// scan finds the matches of the highlight pattern that end in data,
+// which starts at the stream offset position. The end of the previous
+// data is kept in tail so that a match across the two is found.
+// Overlapping matches are merged into one span.
+func (d *dumper) scan(data []byte, position uint64) {
+
+	window := append(d.tail, data...)
+	start := position - uint64(len(d.tail))
+
+	for i := 0; i < len(window); i++ {
+		j := bytes.Index(window[i:], d.highlight)
+		if j < 0 {
+			break
+		}
+		i += j
+
+		match := span{start: start + uint64(i), end: start + uint64(i+len(d.highlight))}
+		if last := len(d.spans) - 1; last >= 0 && match.start <= d.spans[last].end {
+			d.spans[last].end = match.end
+		} else {
+			d.spans = append(d.spans, match)
+		}
+	}
+
+	// A match cannot be complete within the last len-1 bytes, so any
+	// match covering a byte before them has been found
+	keep := len(d.highlight) - 1
+	if keep > len(window) {
+		keep = len(window)
+	}
+	d.tail = append(d.tail[:0], window[len(window)-keep:]...)
+	d.decided = position + uint64(len(data)) - uint64(keep)
+}
+
+// holdLine is used as writeLine when highlighting. The line is copied,
+// as its bytes are reused for the next line, and printed once all its
+// matches are known.
+func (d *dumper) holdLine(line Line) {
+
+	d.held = append(d.held, Line{Offset: line.Offset, Bytes: bytes.Clone(line.Bytes)})
+	d.release()
+}
+
+// release prints the held lines that lie wholly before decided
+func (d *dumper) release() {
+
+	printed := 0
+	for _, line := range d.held {
+		end := line.Offset + uint64(len(line.Bytes))
+		if end > d.decided {
+			break
+		}
+
+		d.print(line)
+		if !d.color {
+			d.printCarets(line)
+		}
+
+		for len(d.spans) > 0 && d.spans[0].end <= end {
+			d.spans = d.spans[1:]
+		}
+		printed++
+	}
+
+	d.held = append(d.held[:0], d.held[printed:]...)
+}
+
+// marked reports whether the byte at the stream offset is part of a
+// match
+func (d *dumper) marked(offset uint64) bool {
+
+	for _, match := range d.spans {
+		if offset < match.start {
+			return false
+		}
+		if offset < match.end {
+			return true
+		}
+	}
+
+	return false
+}
+
+// printCarets prints a line with a "^" under each digit and character
+// of the line that is part of a match. Nothing is printed if the line
+// has no match.
+func (d *dumper) printCarets(line Line) {
+
+	found := false
+	for i := range line.Bytes {
+		found = found || d.marked(line.Offset+uint64(i))
+	}
+	if !found {
+		return
+	}
+
+	var carets strings.Builder
+	var hexCarets strings.Builder
+	var chrCarets strings.Builder
+
+	// The separators are copied rather than counted, so that a tab
+	// lines the carets up as it does the line above
+	if !d.noOffset {
+		indent := len(fmt.Sprintf(d.offsetFormat, d.address(line.Offset)))
+		carets.WriteString(strings.Repeat(" ", indent))
+		carets.WriteString(d.offsetSep)
+	}
+
+	visible := 0
+	for i := 0; i < len(line.Bytes); i += d.word {
+
+		if d.group > 0 && i > 0 && i%d.group == 0 {
+			hexCarets.WriteByte(' ')
+			visible++
+		}
+
+		end := i + d.word
+		if end > len(line.Bytes) {
+			end = len(line.Bytes)
+		}
+		reversed := d.littleEndian && end-i == d.word
+
+		if i > 0 || d.hexLead {
+			hexCarets.WriteByte(' ')
+			visible++
+		}
+
+		for j := i; j < end; j++ {
+			k := j
+			if reversed {
+				k = end - 1 - (j - i)
+			}
+
+			mark := " "
+			if d.marked(line.Offset + uint64(k)) {
+				mark = "^"
+			}
+			hexCarets.WriteString(strings.Repeat(mark, d.byteDigits))
+			visible += d.byteDigits
+		}
+	}
+
+	for i := range line.Bytes {
+		if d.marked(line.Offset + uint64(i)) {
+			chrCarets.WriteByte('^')
+		} else {
+			chrCarets.WriteByte(' ')
+		}
+	}
+
+	switch {
+	case d.asciiFirst:
+		carets.WriteString(chrCarets.String())
+		if !d.separated {
+			carets.WriteString(strings.Repeat(" ", d.displayWidth-len(line.Bytes)))
+		}
+		carets.WriteString(d.hexSeparator())
+		carets.WriteString(hexCarets.String())
+	case d.noASCII:
+		carets.WriteString(hexCarets.String())
+	default:
+		carets.WriteString(hexCarets.String())
+		if !d.separated {
+			carets.WriteString(strings.Repeat(" ", d.hexWidth-visible))
+		}
+		carets.WriteString(d.asciiSep)
+		carets.WriteString(chrCarets.String())
+	}
+
+	d.w.Write([]byte(strings.TrimRight(carets.String(), " ") + "\n"))
+}
@@ -0,0 +1,78 @@
+package hexdump
+
+/*
+	JSON output for a dump. Each line of the dump is written as one
+	JSON object:
+
+		{"offset":16,"bytes":[104,101],"ascii":"he"}
+
+	Edits:
+
+		2026-10-14		lc 		Created from scratch
+		2026-10-14		lc 		EBCDIC text in the ascii field
+		2026-10-14		lc 		Strip7 applied to the ascii field
+
+	Copyright (c) 2020 NOVA Industries Limited
+
+	No warrenty implied or otherwise
+
+*/
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// jsonLine is the JSON layout of a Line. The ASCII text is built by
+// asciiJSON so that it is already quoted.
+type jsonLine struct {
+	Offset uint64          `json:"offset"`
+	Bytes  []int           `json:"bytes"`
+	ASCII  json.RawMessage `json:"ascii"`
+}
+
+// printJSON writes one line of the dump as a JSON object
+func (d *dumper) printJSON(line Line) {
+
+	values := make([]int, len(line.Bytes))
+	for i, ch := range line.Bytes {
+		values[i] = int(ch)
+	}
+
+	d.encoder.Encode(jsonLine{
+		Offset: line.Offset,
+		Bytes:  values,
+		ASCII:  asciiJSON(line.Bytes, d.chars),
+	})
+}
+
+// asciiJSON returns the bytes as a quoted JSON string. Printable ASCII
+// (or EBCDIC, decoded) is kept as it is, every other byte is written
+// as a \u00XX escape rather than the "." used by the text layout.
+func asciiJSON(line []byte, chars charMapper) json.RawMessage {
+
+	var text strings.Builder
+
+	text.WriteByte('"')
+	for _, ch := range line {
+		ch = chars.value(ch)
+		r := rune(ch)
+		if chars.ebcdic {
+			r = ebcdicRunes[ch]
+		}
+
+		switch {
+		case r == '"' || r == '\\':
+			text.WriteByte('\\')
+			text.WriteRune(r)
+		case chars.printable(ch):
+			text.WriteRune(r)
+		default:
+			fmt.Fprintf(&text, "\\u%4.4x", ch)
+		}
+	}
+	text.WriteByte('"')
+
+	return json.RawMessage(text.String())
+}
@@ -0,0 +1,90 @@
+package hexdump
+
+/*
+	Lines gives the lines of a dump one at a time, with each column
+	formatted as text, for callers that draw the dump themselves
+	(a TUI or a progress display) rather than writing it out.
+
+	Edits:
+
+		2026-10-14		lc 		Created from scratch
+		2026-10-14		lc 		Highlight and Header are not used
+		2026-10-14		lc 		Address follows LineNumbers
+		2026-10-14		lc 		Width limited to MaxWidth
+		2026-10-14		lc 		Step is not used
+		2026-10-14		lc 		Grep, Record and the extra columns documented
+
+	Copyright (c) 2020 NOVA Industries Limited
+
+	No warrenty implied or otherwise
+
+*/
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"iter"
+	"strings"
+)
+
+// TextLine is one line of a dump. Line holds the offset and the raw
+// bytes, Address, Hex and ASCII the columns as they would be printed.
+type TextLine struct {
+	Line
+	Address string
+	Hex     string
+	ASCII   string
+}
+
+// Lines returns an iterator over the lines of the dump of r. The layout
+// options are used as by Dump, apart from Format, Header, Highlight,
+// Grep, Squeeze, Step and Trim: every line is given, none are squeezed.
+// TextLine has no columns for Mnemonic, Delta, LineCRC, RightOffset or
+// TimeAt, so they are not used either. Record still starts each record
+// on a new line, but there is no rule between two records. Nothing is
+// read until the iteration starts. Breaking out of
+// the loop stops the reading of r, at most one more buffer is read. A
+// read error is given as the last item of the iteration, with an empty
+// TextLine.
+func Lines(r io.Reader, opts Options) iter.Seq2[TextLine, error] {
+
+	return func(yield func(TextLine, error) bool) {
+
+		if opts.Width > MaxWidth {
+			yield(TextLine{}, ErrWidth)
+			return
+		}
+
+		d := newDumper(nil, opts)
+		d.squeeze = false
+		d.trim = false
+		d.step = 1
+
+		d.writeLine = func(line Line) {
+			if !d.stopped && !yield(d.textLine(line), nil) {
+				d.stopped = true
+			}
+		}
+
+		if err := d.dump(context.Background(), r, opts); err != nil && !d.stopped {
+			yield(TextLine{}, err)
+		}
+	}
+}
+
+// textLine formats the columns of a line. The bytes are copied as the
+// line's own slice is reused for the next line.
+func (d *dumper) textLine(line Line) TextLine {
+
+	hex, _ := d.appendHex(d.lineBuffer[:0], line)
+	d.lineBuffer = hex
+
+	return TextLine{
+		Line:    Line{Offset: line.Offset, Bytes: bytes.Clone(line.Bytes)},
+		Address: fmt.Sprintf(d.offsetFormat, d.address(line.Offset)),
+		Hex:     strings.TrimPrefix(string(hex), " "),
+		ASCII:   string(d.appendASCII(nil, line)),
+	}
+}
@@ -0,0 +1,89 @@
+package hexdump
+
+/*
+	A small table of the magic numbers that start well known file
+	formats, used to label a dump with a guess at what it is.
+
+	Edits:
+
+		2026-10-14		lc 		Created from scratch
+
+	Copyright (c) 2020 NOVA Industries Limited
+
+	No warrenty implied or otherwise
+
+*/
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// magicNumber is the bytes a format has at offset from the start of
+// the file
+type magicNumber struct {
+	offset int
+	bytes  []byte
+	name   string
+}
+
+// magicNumbers is checked in order, so a longer number goes before a
+// shorter one that it starts with
+var magicNumbers = []magicNumber{
+	{0, []byte("\x89PNG\r\n\x1a\n"), "PNG image"},
+	{0, []byte("\xff\xd8\xff"), "JPEG image"},
+	{0, []byte("GIF87a"), "GIF image"},
+	{0, []byte("GIF89a"), "GIF image"},
+	{0, []byte("%PDF-"), "PDF document"},
+	{0, []byte("PK\x03\x04"), "ZIP archive"},
+	{0, []byte("PK\x05\x06"), "ZIP archive (empty)"},
+	{0, []byte("\x1f\x8b"), "gzip"},
+	{0, []byte("BZh"), "bzip2"},
+	{0, []byte("\xfd7zXZ\x00"), "xz"},
+	{0, []byte("\x28\xb5\x2f\xfd"), "zstd"},
+	{0, []byte("7z\xbc\xaf\x27\x1c"), "7-Zip archive"},
+	{257, []byte("ustar"), "tar archive"},
+	{0, []byte("\x7fELF"), "ELF executable"},
+	{0, []byte("MZ"), "DOS/Windows executable"},
+	{0, []byte("\xfe\xed\xfa\xce"), "Mach-O executable"},
+	{0, []byte("\xfe\xed\xfa\xcf"), "Mach-O executable"},
+	{0, []byte("\xce\xfa\xed\xfe"), "Mach-O executable"},
+	{0, []byte("\xcf\xfa\xed\xfe"), "Mach-O executable"},
+	{0, []byte("\xca\xfe\xba\xbe"), "Java class or Mach-O universal binary"},
+	{0, []byte("\x00asm"), "WebAssembly module"},
+	{0, []byte("SQLite format 3\x00"), "SQLite database"},
+	{0, []byte("OggS"), "Ogg media"},
+	{0, []byte("fLaC"), "FLAC audio"},
+	{0, []byte("ID3"), "MP3 audio"},
+	{0, []byte("\xef\xbb\xbf"), "UTF-8 text with BOM"},
+	{0, []byte("#!"), "script"},
+}
+
+// FileType returns a guess at the format of a file from the magic
+// number at the start of data, such as "PNG image" or "gzip", or "" if
+// it is not one in the table. data only needs to be the first few
+// hundred bytes of the file.
+func FileType(data []byte) string {
+
+	for _, magic := range magicNumbers {
+		end := magic.offset + len(magic.bytes)
+		if end <= len(data) && bytes.Equal(data[magic.offset:end], magic.bytes) {
+			return magic.name
+		}
+	}
+
+	return ""
+}
+
+// printMagic prints the guess at the format of the data a dump starts
+// with, on a line of its own before the dump
+func (d *dumper) printMagic(data []byte) {
+
+	d.magic = false
+
+	name := FileType(data)
+	if name == "" {
+		name = "unknown"
+	}
+	fmt.Fprintf(d.w, "Type: %s\n", name)
+}
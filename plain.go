@@ -0,0 +1,86 @@
+package hexdump
+
+/*
+	Plain writes a stream as a continuous run of hex digits, in the
+	style of "xxd -p", with no offset or ASCII columns, for pasting
+	into other tools:
+
+		68656c6c6f20776f726c642c2074686973206973206120746573742066
+		6f72207468652068657864756d700a
+
+	Edits:
+
+		2026-10-14		lc 		Created from scratch
+		2026-10-14		lc 		Find option applied
+		2026-10-14		lc 		BufferSize option applied
+		2026-10-14		lc 		An odd number of columns rounded up
+
+	Copyright (c) 2020 NOVA Industries Limited
+
+	No warrenty implied or otherwise
+
+*/
+
+import (
+	"bufio"
+	"io"
+)
+
+const PlainColumns = 60 // default hex digits per line of Plain
+
+// Plain writes the content of the stream r to w as hex digits, with a
+// new line after every columns digits (PlainColumns if columns is not
+// more than zero). An odd number of columns is rounded up, so that the
+// two digits of a byte are never split over two lines. The Skip, Find,
+// Length, Tee, BufferSize and Upper options are used, the rest only
+// apply to Dump. Empty input gives no output.
+func Plain(w io.Writer, r io.Reader, columns int, opts Options) error {
+
+	r, _, err := limitInput(r, opts)
+	if err != nil {
+		return err
+	}
+
+	if columns <= 0 {
+		columns = PlainColumns
+	}
+	columns += columns % 2
+
+	hexDigits := lowerHexDigits
+	if opts.Upper {
+		hexDigits = upperHexDigits
+	}
+
+	out := bufio.NewWriter(w)
+	buffer := make([]byte, readBufferSize(opts))
+	column := 0
+
+	for {
+		bufferRead, err := r.Read(buffer)
+
+		for _, ch := range buffer[:bufferRead] {
+			for _, digit := range [2]byte{hexDigits[ch>>4], hexDigits[ch&0x0F]} {
+				if column == columns {
+					out.WriteByte('\n')
+					column = 0
+				}
+				out.WriteByte(digit)
+				column++
+			}
+		}
+
+		if err != nil {
+			if err != io.EOF {
+				out.Flush()
+				return err
+			}
+			break
+		}
+	}
+
+	if column > 0 {
+		out.WriteByte('\n')
+	}
+
+	return out.Flush()
+}
@@ -0,0 +1,80 @@
+package hexdump
+
+/*
+	Fixed size records, where every Record bytes of the stream start a
+	new line and the records are split by a dashed rule:
+
+		0000 :  01 00 00 00 61 6c 69 63 65 00 00 00  : ....alice...
+		--------------------------------------------------------
+		000c :  02 00 00 00 62 6f 62 00 00 00 00 00  : ....bob.....
+
+	Edits:
+
+		2026-10-14		lc 		Created from scratch
+		2026-10-14		lc 		Rule as wide as an ASCIIFirst line
+		2026-10-14		lc 		SqueezeCount marker at the end of a record
+		2026-10-14		lc 		Rule as wide as a line without offsets
+
+	Copyright (c) 2020 NOVA Industries Limited
+
+	No warrenty implied or otherwise
+
+*/
+
+import (
+	"fmt"
+	"strings"
+)
+
+// lineRoom returns the number of bytes that fit on the rest of the line
+// that the stream offset position falls in. Lines are on width
+// boundaries of the stream or, with records, of the record, and a line
+// never runs on past the end of a record.
+func (d *dumper) lineRoom(position uint64) int {
+
+	width := uint64(d.displayWidth)
+	if d.record == 0 {
+		return int(width - position%width)
+	}
+
+	inRecord := position % d.record
+	return int(min(width-inRecord%width, d.record-inRecord))
+}
+
+// endRecord is called after each line of a record dump. At the end of a
+// record any run of padding or squeezed lines is finished, so that no
+// run carries on into the next record, and the rule is left to be
+// printed before the next line.
+func (d *dumper) endRecord(line Line) {
+
+	if (line.Offset+uint64(len(line.Bytes)))%d.record != 0 {
+		return
+	}
+
+	d.endPadding()
+	if d.squeezing {
+		d.endSqueeze(uint64(len(d.previous)))
+		d.writeLine(Line{Offset: d.lastOffset, Bytes: d.previous})
+		d.squeezing = false
+	}
+	d.previous = nil
+
+	d.ruling = d.ruled
+}
+
+// printRule prints the dashed line between two records, as wide as a
+// full line of the dump
+func (d *dumper) printRule() {
+
+	d.ruling = false
+
+	width := d.offsetWidth() + d.hexWidth
+	switch {
+	case d.asciiFirst:
+		width += d.displayWidth + len(d.hexSeparator())
+	case !d.noASCII:
+		width += len(d.asciiSep) + d.displayWidth
+	}
+
+	fmt.Fprintln(d.w, strings.Repeat("-", width))
+}
@@ -0,0 +1,242 @@
+package hexdump
+
+/*
+	Reverse converts a dump produced by this package back into the
+	original bytes.
+
+	Edits:
+
+		2026-10-14		lc 		Created from scratch
+		2026-10-14		lc 		Expand trimmed runs of padding
+		2026-10-14		lc 		Read lines with no ASCII column
+		2026-10-14		lc 		Read the SqueezeCount marker
+		2026-10-14		lc 		ReverseRadix for decimal and octal offsets
+		2026-10-14		lc 		Hex column ends at the next " : " column
+
+	Copyright (c) 2020 NOVA Industries Limited
+
+	No warrenty implied or otherwise
+
+*/
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Reverse reads a dump from r and writes the reconstructed binary to w.
+// Only the hex column of each line is used, the offset and (any) ASCII
+// columns are ignored apart from using the offsets to expand a "*"
+// squeeze line, or a SqueezeCount one, back into the repeated lines. A
+// trimmed "[N bytes of XX]" line is expanded back into its N bytes.
+// Lines that do not match the dump layout are skipped. Colour escapes
+// are removed before a line is parsed. The offsets are read as hex, use
+// ReverseRadix for a dump made with another Radix.
+func Reverse(w io.Writer, r io.Reader) error {
+
+	return ReverseRadix(w, r, Hex)
+}
+
+// ReverseRadix is Reverse for a dump whose offset column is in the
+// given radix. Reading the offsets in the wrong radix would size the
+// expanded squeeze lines wrongly, so a line whose offset is not a
+// number in radix is skipped as not in the dump layout.
+func ReverseRadix(w io.Writer, r io.Reader, radix Radix) error {
+
+	base, ok := radixBases[radix]
+	if !ok {
+		base = 16
+	}
+
+	scanner := bufio.NewScanner(r)
+
+	var previous []byte
+	var previousOffset uint64
+	squeezed := false
+
+	for scanner.Scan() {
+		text := stripColor(scanner.Text())
+
+		if isSqueezeMarker(text) {
+			squeezed = previous != nil
+			continue
+		}
+
+		offset, line, ok := parseLine(text, base)
+		padOffset, padLength, padByte, isPad := parsePadding(text, base)
+		if !ok && !isPad {
+			continue
+		}
+		if isPad {
+			offset = padOffset
+		}
+
+		if squeezed && len(previous) > 0 {
+			for pos := previousOffset + uint64(len(previous)); pos < offset; pos += uint64(len(previous)) {
+				if _, err := w.Write(previous); err != nil {
+					return err
+				}
+			}
+			squeezed = false
+		}
+
+		if isPad {
+			if err := writePadding(w, padLength, padByte); err != nil {
+				return err
+			}
+			// A squeeze never carries on over a run of padding
+			previous = nil
+			continue
+		}
+
+		if _, err := w.Write(line); err != nil {
+			return err
+		}
+
+		previous = line
+		previousOffset = offset
+	}
+
+	return scanner.Err()
+}
+
+// radixBases maps a Radix to the number base of its offsets
+var radixBases = map[Radix]int{
+	Hex:     16,
+	Decimal: 10,
+	Octal:   8,
+}
+
+// isSqueezeMarker reports whether the line is a "*" squeeze line, with
+// or without the count of SqueezeCount
+func isSqueezeMarker(text string) bool {
+
+	text = strings.TrimSpace(text)
+	if text == squeezeMarker {
+		return true
+	}
+
+	var n uint64
+	if _, err := fmt.Sscanf(text, squeezeCounted, &n); err != nil {
+		return false
+	}
+
+	return fmt.Sprintf(squeezeCounted, n) == text
+}
+
+// stripColor removes any ANSI colour escapes from a line
+func stripColor(text string) string {
+
+	for {
+		i := strings.Index(text, "\x1b[")
+		if i < 0 {
+			return text
+		}
+
+		j := strings.IndexByte(text[i:], 'm')
+		if j < 0 {
+			return text
+		}
+		text = text[:i] + text[i+j+1:]
+	}
+}
+
+// parseLine splits a line of a dump into its offset, in the given base,
+// and bytes. ok is false if the line is not laid out as a dump line
+func parseLine(text string, base int) (offset uint64, line []byte, ok bool) {
+
+	i := strings.Index(text, offsetSeparator)
+	if i < 0 {
+		return
+	}
+
+	offset, err := strconv.ParseUint(strings.TrimSpace(text[:i]), base, 64)
+	if err != nil {
+		return
+	}
+
+	// The hex digits end at the next column: the character column,
+	// whose asciiSeparator ends in a " : " too, or in a dump made with
+	// NoASCII any Delta, LineCRC or RightOffset column. Without any of
+	// them they run to the end of the line.
+	hexDigits := text[i+len(offsetSeparator):]
+	if j := strings.Index(hexDigits, offsetSeparator); j >= 0 {
+		hexDigits = hexDigits[:j]
+	}
+
+	for _, field := range strings.Fields(hexDigits) {
+		if len(field) != 2 {
+			return
+		}
+
+		ch, err := strconv.ParseUint(field, 16, 8)
+		if err != nil {
+			return
+		}
+		line = append(line, byte(ch))
+	}
+
+	ok = true
+	return
+}
+
+// parsePadding splits a trimmed run of padding, as printed by Dump with
+// Trim set, into its offset, in the given base, length and byte. ok is
+// false if the line is not such a run.
+func parsePadding(text string, base int) (offset uint64, length uint64, ch byte, ok bool) {
+
+	i := strings.Index(text, offsetSeparator)
+	if i < 0 {
+		return
+	}
+
+	offset, err := strconv.ParseUint(strings.TrimSpace(text[:i]), base, 64)
+	if err != nil {
+		return
+	}
+
+	marker := strings.TrimSpace(text[i+len(offsetSeparator):])
+	if !strings.HasPrefix(marker, "[") || !strings.HasSuffix(marker, "]") {
+		return
+	}
+
+	fields := strings.Fields(marker[1 : len(marker)-1])
+	if len(fields) != 4 || fields[1] != "bytes" || fields[2] != "of" || len(fields[3]) != 2 {
+		return
+	}
+
+	length, err = strconv.ParseUint(fields[0], 10, 64)
+	if err != nil {
+		return
+	}
+
+	value, err := strconv.ParseUint(fields[3], 16, 8)
+	if err != nil {
+		return
+	}
+
+	ch = byte(value)
+	ok = true
+	return
+}
+
+// writePadding writes length copies of ch to w, a buffer at a time so
+// that a long run does not need a buffer of its own size
+func writePadding(w io.Writer, length uint64, ch byte) error {
+
+	chunk := bytes.Repeat([]byte{ch}, int(min(length, bufferSize)))
+
+	for length > 0 {
+		n := min(length, uint64(len(chunk)))
+		if _, err := w.Write(chunk[:n]); err != nil {
+			return err
+		}
+		length -= n
+	}
+
+	return nil
+}
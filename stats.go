@@ -0,0 +1,231 @@
+package hexdump
+
+/*
+	Byte frequency statistics for a stream, used to judge at a glance
+	whether a blob is text, compressed or encrypted.
+
+	Edits:
+
+		2026-10-14		lc 		Created from scratch
+		2026-10-14		lc 		Find option applied
+		2026-10-14		lc 		Added the histogram chart
+		2026-10-14		lc 		Count of the bytes only
+		2026-10-14		lc 		"1 time" rather than "1 times" in the report
+
+	Copyright (c) 2020 NOVA Industries Limited
+
+	No warrenty implied or otherwise
+
+*/
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Stats is a histogram of the byte values in a stream. It is an
+// io.Writer so a stream can be copied, or teed, into it.
+type Stats struct {
+	Counts [256]uint64
+	Total  uint64
+}
+
+// Write adds the bytes in p to the histogram. It never fails.
+func (s *Stats) Write(p []byte) (int, error) {
+
+	for _, ch := range p {
+		s.Counts[ch]++
+	}
+	s.Total += uint64(len(p))
+
+	return len(p), nil
+}
+
+// Entropy returns the Shannon entropy of the bytes in bits per byte,
+// from 0 (every byte the same) to 8 (random data)
+func (s *Stats) Entropy() float64 {
+
+	if s.Total == 0 {
+		return 0
+	}
+
+	entropy := 0.0
+	for _, count := range s.Counts {
+		if count > 0 {
+			p := float64(count) / float64(s.Total)
+			entropy -= p * math.Log2(p)
+		}
+	}
+
+	return entropy
+}
+
+// MostCommon returns the byte value seen most often and its count.
+// Ties go to the lowest byte value.
+func (s *Stats) MostCommon() (byte, uint64) {
+
+	most := 0
+	for i, count := range s.Counts {
+		if count > s.Counts[most] {
+			most = i
+		}
+	}
+
+	return byte(most), s.Counts[most]
+}
+
+// LeastCommon returns the byte value seen least often, of those that
+// appear at all, and its count. Ties go to the lowest byte value.
+func (s *Stats) LeastCommon() (byte, uint64) {
+
+	least := -1
+	for i, count := range s.Counts {
+		if count > 0 && (least < 0 || count < s.Counts[least]) {
+			least = i
+		}
+	}
+
+	if least < 0 {
+		return 0, 0
+	}
+	return byte(least), s.Counts[least]
+}
+
+// Distinct returns the number of different byte values seen
+func (s *Stats) Distinct() int {
+
+	distinct := 0
+	for _, count := range s.Counts {
+		if count > 0 {
+			distinct++
+		}
+	}
+
+	return distinct
+}
+
+// Printable returns the number of printable ASCII bytes seen
+func (s *Stats) Printable() uint64 {
+
+	var printable uint64
+	for ch := chSpace; ch < chDel; ch++ {
+		printable += s.Counts[ch]
+	}
+
+	return printable
+}
+
+// Report writes a summary of the statistics to w
+func (s *Stats) Report(w io.Writer) {
+
+	fmt.Fprintf(w, "Total bytes:     %d\n", s.Total)
+	fmt.Fprintf(w, "Entropy:         %.4f bits/byte\n", s.Entropy())
+
+	if s.Total > 0 {
+		most, mostCount := s.MostCommon()
+		least, leastCount := s.LeastCommon()
+		fmt.Fprintf(w, "Most common:     0x%2.2x (%s)\n", most, times(mostCount))
+		fmt.Fprintf(w, "Least common:    0x%2.2x (%s)\n", least, times(leastCount))
+	}
+
+	fmt.Fprintf(w, "Distinct values: %d\n", s.Distinct())
+	fmt.Fprintf(w, "Printable:       %d\n", s.Printable())
+	fmt.Fprintf(w, "Non-printable:   %d\n", s.Total-s.Printable())
+}
+
+// times gives a count of how often a value was seen, "1 time" or
+// "8 times"
+func times(count uint64) string {
+
+	if count == 1 {
+		return "1 time"
+	}
+
+	return fmt.Sprintf("%d times", count)
+}
+
+// minBarWidth is the narrowest the bars of a Chart are drawn, however
+// narrow the width asked for
+const minBarWidth = 10
+
+// Chart writes the histogram to w as a bar chart, one line for each
+// byte value from 0x00 to 0xff, with the line for the most common value
+// filling width characters. A value that is seen at all gets a bar at
+// least one character long.
+func (s *Stats) Chart(w io.Writer, width int) {
+
+	_, most := s.MostCommon()
+	digits := len(strconv.FormatUint(most, 10))
+
+	// Room is left for "41 " before the bar and " count" after it
+	bars := max(width-3-1-digits, minBarWidth)
+
+	for ch, count := range s.Counts {
+		length := 0
+		if count > 0 {
+			length = max(int(count*uint64(bars)/most), 1)
+		}
+		fmt.Fprintf(w, "%2.2x %-*s %*d\n", ch, bars, strings.Repeat("#", length), digits, count)
+	}
+}
+
+// Histogram reads the stream r, after applying the Skip, Find, Length
+// and Tee options, and writes a bar chart of how often each byte value
+// is seen to w, width characters wide
+func Histogram(w io.Writer, r io.Reader, width int, opts Options) error {
+
+	stats, err := collectStats(r, opts)
+	if err != nil {
+		return err
+	}
+
+	stats.Chart(w, width)
+	return nil
+}
+
+// Statistics reads the stream r, after applying the Skip, Find, Length
+// and Tee options, and writes a summary of its byte statistics to w
+func Statistics(w io.Writer, r io.Reader, opts Options) error {
+
+	stats, err := collectStats(r, opts)
+	if err != nil {
+		return err
+	}
+
+	stats.Report(w)
+	return nil
+}
+
+// Count reads the stream r, after applying the Skip, Find, Length and
+// Tee options, and returns the number of bytes read. The count so far
+// is returned with a read error.
+func Count(r io.Reader, opts Options) (uint64, error) {
+
+	r, _, err := limitInput(r, opts)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := io.Copy(io.Discard, r)
+	return uint64(n), err
+}
+
+// collectStats reads the stream r, after applying the Skip, Find,
+// Length and Tee options, into a new histogram
+func collectStats(r io.Reader, opts Options) (*Stats, error) {
+
+	r, _, err := limitInput(r, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var stats Stats
+	if _, err := io.Copy(&stats, r); err != nil {
+		return nil, err
+	}
+
+	return &stats, nil
+}
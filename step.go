@@ -0,0 +1,60 @@
+package hexdump
+
+/*
+	Sampling of a dump, where only every Step'th line is printed to
+	give an overview of a large file. The offsets of the lines that
+	are printed are not changed, so the gaps show in the offsets.
+
+	Edits:
+
+		2026-10-14		lc 		Created from scratch
+		2026-10-14		lc 		Last sampled line not counted twice in Totals
+		2026-10-14		lc 		No record rule before the last line of a record
+
+	Copyright (c) 2020 NOVA Industries Limited
+
+	No warrenty implied or otherwise
+
+*/
+
+// dropLine reports whether the line is left out by sampling. The last
+// line left out is kept, so that finish can print it if it turns out to
+// be the last line of the stream.
+func (d *dumper) dropLine(line Line) bool {
+
+	d.stepIndex++
+	if (d.stepIndex-1)%d.step == 0 {
+		d.dropped = false
+		return false
+	}
+
+	d.lastDropped.Offset = line.Offset
+	d.lastDropped.Bytes = append(d.lastDropped.Bytes[:0], line.Bytes...)
+	d.dropped = true
+
+	// The line's own record end is yet to be seen, so this is whether
+	// a rule goes before it
+	d.droppedRuling = d.ruling
+
+	return true
+}
+
+// endSample prints the last line of the stream if sampling left it out,
+// so that the end of the stream is always shown
+func (d *dumper) endSample() {
+
+	if !d.dropped {
+		return
+	}
+
+	// Reset before the line goes back through formatLine so it is not
+	// sampled, or added to the totals, again. A rule due after the line
+	// has to wait until it is printed.
+	d.dropped = false
+	d.ruling = d.droppedRuling
+	d.step = 1
+	totals := d.totals
+	d.totals = nil
+	d.formatLine(d.lastDropped)
+	d.totals = totals
+}
@@ -0,0 +1,47 @@
+package hexdump
+
+/*
+	Template output for a dump, where each line is laid out by a Go
+	text/template given by the caller, such as:
+
+		{{printf "%08x" .Offset}}  {{.Hex}}  |{{.Ascii}}|
+
+	Edits:
+
+		2026-10-14		lc 		Created from scratch
+
+	Copyright (c) 2020 NOVA Industries Limited
+
+	No warrenty implied or otherwise
+
+*/
+
+// templateLine is the data a Template is run with. Offset and Bytes
+// come from the Line, Address, Hex and ASCII are the text columns.
+type templateLine struct {
+	TextLine
+}
+
+// Ascii is the ASCII column, under the name the command line documents
+func (t templateLine) Ascii() string {
+
+	return t.ASCII
+}
+
+// printTemplate writes one line of the dump by running the Template on
+// it, followed by a newline. An error from the template stops the dump
+// and is returned by Dump.
+func (d *dumper) printTemplate(line Line) {
+
+	if d.failure != nil {
+		return
+	}
+
+	if err := d.template.Execute(d.w, templateLine{d.textLine(line)}); err != nil {
+		d.failure = err
+		d.stopped = true
+		return
+	}
+
+	d.w.Write([]byte{'\n'})
+}
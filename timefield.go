@@ -0,0 +1,96 @@
+package hexdump
+
+/*
+	The TimeAt annotation, a Unix time held at a fixed place in each
+	record, shown decoded at the end of the line where it ends:
+
+		0000 :  01 00 00 00 40 59 a3 66 00 00 00 00 00 00 00 00  : ....@Y.f........  : 2024-07-26T08:07:28Z
+
+	Edits:
+
+		2026-10-14		lc 		Created from scratch
+
+	Copyright (c) 2020 NOVA Industries Limited
+
+	No warrenty implied or otherwise
+
+*/
+
+import (
+	"encoding/binary"
+	"time"
+)
+
+const invalidTime = "invalid"
+
+// TimeField is where a Unix time, in seconds, is held in each record for
+// Options.TimeAt. Offset is from the start of the record. Size is 4 for
+// a 32bit time or 8 for a 64bit one, both signed.
+type TimeField struct {
+	Offset       uint64
+	Size         int
+	LittleEndian bool
+}
+
+// fits reports whether the field is a size that can be decoded and
+// lies within a record of the given size
+func (f *TimeField) fits(record uint64) bool {
+
+	return (f.Size == 4 || f.Size == 8) && f.Offset < record && uint64(f.Size) <= record-f.Offset
+}
+
+// noteTime collects the bytes of the time field that fall in the line,
+// and sets timeText to the decoded time if the field ends in the line,
+// or to "" if not. A field that is not all in the dump, because of
+// Skip, Length or a short last record, is not shown.
+func (d *dumper) noteTime(line Line) {
+
+	d.timeText = ""
+
+	start := line.Offset - line.Offset%d.record + d.timeAt.Offset
+	end := start + uint64(d.timeAt.Size)
+	if start != d.timeStart {
+		d.timeStart = start
+		d.timeHave = 0
+	}
+
+	for i, ch := range line.Bytes {
+		position := line.Offset + uint64(i)
+		if position >= start && position < end {
+			d.timeBytes[position-start] = ch
+			d.timeHave++
+		}
+	}
+
+	lineEnd := line.Offset + uint64(len(line.Bytes))
+	if end > line.Offset && end <= lineEnd && d.timeHave == d.timeAt.Size {
+		d.timeText = d.decodeTime()
+	}
+}
+
+// decodeTime returns the collected time field as an RFC 3339 UTC time,
+// or "invalid" if its year is outside 0 to 9999
+func (d *dumper) decodeTime() string {
+
+	var order binary.ByteOrder = binary.BigEndian
+	if d.timeAt.LittleEndian {
+		order = binary.LittleEndian
+	}
+
+	var seconds int64
+	if d.timeAt.Size == 4 {
+		seconds = int64(int32(order.Uint32(d.timeBytes[:4])))
+	} else {
+		seconds = int64(order.Uint64(d.timeBytes[:8]))
+	}
+
+	// time.Unix takes any int64, but far out ones are not real dates
+	// and overflow the year
+	const maxSeconds = 253402300799 // 9999-12-31T23:59:59Z
+	const minSeconds = -62167219200 // 0000-01-01T00:00:00Z
+	if seconds > maxSeconds || seconds < minSeconds {
+		return invalidTime
+	}
+
+	return time.Unix(seconds, 0).UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,89 @@
+package hexdump
+
+/*
+	Collapsing of runs of padding lines, those made only of 0x00 or
+	only of 0x20 bytes, into a single line giving the run's length:
+
+		0200 :  [768 bytes of 00]
+
+	Edits:
+
+		2026-10-14		lc 		Created from scratch
+		2026-10-14		lc 		Follow the column separator
+		2026-10-14		lc 		Line numbers in the offset column
+		2026-10-14		lc 		SqueezeCount marker before a run of padding
+		2026-10-14		lc 		Padding lines without the offset column
+
+	Copyright (c) 2020 NOVA Industries Limited
+
+	No warrenty implied or otherwise
+
+*/
+
+import "fmt"
+
+// isPadding reports whether every byte of the line is the same padding
+// byte, 0x00 or 0x20
+func isPadding(line []byte) bool {
+
+	if len(line) == 0 || line[0] != 0x00 && line[0] != chSpace {
+		return false
+	}
+
+	for _, ch := range line {
+		if ch != line[0] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// addPadding adds the line to the current run of padding, starting a
+// new run if needed, and reports whether it did. A line that is not
+// padding is left for the caller to print.
+func (d *dumper) addPadding(line Line) bool {
+
+	if !isPadding(line.Bytes) {
+		return false
+	}
+
+	if d.padding && d.padByte == line.Bytes[0] {
+		d.padLength += uint64(len(line.Bytes))
+		return true
+	}
+
+	d.endPadding()
+	d.endSqueeze(0)
+
+	d.padding = true
+	d.padByte = line.Bytes[0]
+	d.padOffset = line.Offset
+	d.padLength = uint64(len(line.Bytes))
+	return true
+}
+
+// endPadding prints the line for the current run of padding, if there
+// is one. A squeeze does not carry on over the run, so the line after
+// it is always printed.
+func (d *dumper) endPadding() {
+
+	if !d.padding {
+		return
+	}
+
+	if d.header {
+		d.printHeader()
+	}
+
+	digits := string([]byte{d.hexDigits[d.padByte>>4], d.hexDigits[d.padByte&0x0F]})
+	marker := fmt.Sprintf(paddingFormat, d.padLength, digits)
+	if !d.separated && !d.noOffset {
+		marker = " " + marker
+	}
+	d.w.Write(append(d.appendOffset(nil, d.padOffset), marker+"\n"...))
+
+	d.padding = false
+	d.previous = nil
+	d.squeezing = false
+}